@@ -0,0 +1,162 @@
+package gitlabcodeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// aliasDirectiveRegex matches a comment like `# alias @payments = @alice @bob`
+// which defines an alias for a list of owners.
+var aliasDirectiveRegex = regexp.MustCompile(`^#\s*alias\s+(\S*)\s*=(.*)$`)
+
+// aliases maps the name of each alias to the owners it expands to.
+type aliases map[string][]string
+
+// parseAliasDirective returns the name and the owners of the alias defined
+// by the comment line, the boolean is false if the line is no directive.
+// Aliases used in the owners are expanded with the already defined aliases.
+func (a aliases) parseAliasDirective(line string) (string, []string, bool, error) {
+	match := aliasDirectiveRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", nil, false, nil
+	}
+
+	name, owners := match[1], strings.Fields(match[2])
+
+	switch {
+	case !strings.HasPrefix(name, "@") || len(name) == 1:
+		return "", nil, true, ErrInvalidAliasName
+	case len(owners) == 0:
+		return "", nil, true, ErrEmptyAlias
+	}
+
+	expanded, _ := a.expand(owners)
+
+	return name, expanded, true, nil
+}
+
+// rewriteAliasDirective returns the alias directive with the owners changed
+// by the function. The boolean is false if the line is no directive or its
+// owners are not changed.
+func rewriteAliasDirective(line string, rewrite func(owners []string) ([]string, bool)) (string, bool) {
+	match := aliasDirectiveRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return line, false
+	}
+
+	owners, changed := rewrite(strings.Fields(match[2]))
+	if !changed {
+		return line, false
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("# alias %s = %s", match[1], strings.Join(owners, " "))), true
+}
+
+// expand replaces every alias in the owners with the owners it stands for,
+// in which case duplicated owners are removed. If at least one alias was
+// replaced the given owners are returned as well, otherwise they are nil.
+func (a aliases) expand(owners []string) ([]string, []string) {
+	if len(a) == 0 {
+		return owners, nil
+	}
+
+	expanded := make([]string, 0, len(owners))
+	seen := map[string]bool{}
+	replaced := false
+
+	for _, owner := range owners {
+		members, ok := a[owner]
+		if !ok {
+			members = []string{owner}
+		}
+
+		replaced = replaced || ok
+
+		for _, member := range members {
+			if !seen[member] {
+				seen[member] = true
+				expanded = append(expanded, member)
+			}
+		}
+	}
+
+	if !replaced {
+		return owners, nil
+	}
+
+	return expanded, owners
+}
+
+// isAliasDirective reports if the line defines an alias.
+func isAliasDirective(line string) bool {
+	return aliasDirectiveRegex.MatchString(strings.TrimSpace(line))
+}
+
+// ExpandAliases writes the owners of all section headers and rules which
+// use an alias as plain owners and removes the alias definitions, so the
+// file can be used by Gitlab. The headers of duplicate and empty sections,
+// which are kept as written, are expanded as well.
+func (f *File) ExpandAliases() {
+	*f = f.clone()
+	defined := aliases{}
+
+	for i := range f.sections {
+		sec := &f.sections[i]
+		sec.src.leading = defined.expandLines(sec.src.leading)
+
+		if sec.aliasedOwners != nil {
+			sec.src.raw = ""
+			sec.aliasedOwners = nil
+		}
+
+		for j := range sec.rules {
+			r := &sec.rules[j]
+			r.src.leading = defined.expandLines(r.src.leading)
+
+			if r.aliasedOwners != nil {
+				r.src.raw = ""
+				r.aliasedOwners = nil
+			}
+		}
+	}
+
+	f.trailing = defined.expandLines(f.trailing)
+}
+
+// expandLines expands the aliases in the section headers of the lines and
+// returns the lines without the alias directives. The directives in the
+// lines are added to the aliases, so they apply to the following lines.
+func (a aliases) expandLines(lines []string) []string {
+	expand := func(owners []string) ([]string, bool) {
+		expanded, written := a.expand(owners)
+
+		return expanded, written != nil
+	}
+
+	for i, line := range lines {
+		if name, owners, ok, err := a.parseAliasDirective(line); ok {
+			if err == nil {
+				a[name] = owners
+			}
+
+			continue
+		}
+
+		rewriteOwnerLines(lines[i:i+1], expand)
+	}
+
+	return withoutAliasDirectives(lines)
+}
+
+func withoutAliasDirectives(lines []string) []string {
+	var result []string
+
+	for _, line := range lines {
+		if !isAliasDirective(line) {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,177 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const aliasTestFile = `# alias @payments = @alice @bob @payments-oncall
+# alias @reviewers = @payments @carol
+[Payments] @payments
+/payments/
+/billing/ @reviewers @alice # legacy billing
+/docs/ @docs
+`
+
+func TestAlias_parseAliasDirective(t *testing.T) {
+	t.Parallel()
+
+	defined := aliases{"@team": {"@alice", "@bob"}}
+
+	tests := []struct {
+		name       string
+		line       string
+		wantName   string
+		wantOwners []string
+		wantOk     bool
+		wantErr    error
+	}{
+		{
+			name:   "plain comment",
+			line:   "# the alias is defined below",
+			wantOk: false,
+		},
+		{
+			name:       "alias",
+			line:       "# alias @payments = @alice @payments-oncall",
+			wantName:   "@payments",
+			wantOwners: []string{"@alice", "@payments-oncall"},
+			wantOk:     true,
+		},
+		{
+			name:       "alias using another alias",
+			line:       "#alias @all=@team @carol @bob",
+			wantName:   "@all",
+			wantOwners: []string{"@alice", "@bob", "@carol"},
+			wantOk:     true,
+		},
+		{
+			name:    "name without at sign",
+			line:    "# alias payments = @alice",
+			wantOk:  true,
+			wantErr: ErrInvalidAliasName,
+		},
+		{
+			name:    "no owners",
+			line:    "# alias @payments =",
+			wantOk:  true,
+			wantErr: ErrEmptyAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			name, owners, ok, err := defined.parseAliasDirective(tt.line)
+
+			if name != tt.wantName || ok != tt.wantOk {
+				t.Errorf("got (%s, %t), wanted (%s, %t)", name, ok, tt.wantName, tt.wantOk)
+			}
+
+			testhelper.DeepEqual(t, owners, tt.wantOwners)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, wanted %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlias_expandDuringParsing(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(aliasTestFile))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	got := file.GetRequiredApprovalsForFiles([]string{"/payments/api.go", "/billing/invoice.go", "/docs/index.md"})
+	want := map[string][]Approval{
+		"Payments": {
+			{Pattern: "/payments/", Approvals: 1, Owners: []string{"@alice", "@bob", "@payments-oncall"}},
+			{
+				Pattern:   "/billing/",
+				Approvals: 1,
+				Owners:    []string{"@alice", "@bob", "@payments-oncall", "@carol"},
+				Comment:   "legacy billing",
+			},
+			{Pattern: "/docs/", Approvals: 1, Owners: []string{"@docs"}},
+		},
+	}
+	testhelper.DeepEqual(t, got, want)
+
+	if got := file.String(); got != aliasTestFile {
+		t.Errorf("got %q, wanted the unchanged file", got)
+	}
+}
+
+func TestAlias_ExpandAliases(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(aliasTestFile))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	file.ExpandAliases()
+
+	want := `[Payments] @alice @bob @payments-oncall
+/payments/
+/billing/ @alice @bob @payments-oncall @carol # legacy billing
+/docs/ @docs
+`
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestAlias_ExpandAliasesInDuplicateSection(t *testing.T) {
+	t.Parallel()
+
+	content := `# alias @p = @alice @bob
+[Docs] @p
+/docs/
+[Docs][2] @p
+[Empty] @p @carol
+`
+
+	file, err := NewCodeOwnersFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	file.ExpandAliases()
+
+	want := `[Docs] @alice @bob
+/docs/
+[Docs][2] @alice @bob
+[Empty] @alice @bob @carol
+`
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestAlias_invalidDirective(t *testing.T) {
+	t.Parallel()
+
+	content := "# alias payments = @alice\n* @payments\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	want := []*ParseError{{Line: 1, Column: 1, Text: "# alias payments = @alice", Err: ErrInvalidAliasName}}
+	testhelper.DeepEqual(t, file.ParseErrors(), want)
+
+	if _, err := NewCodeOwnersFile(strings.NewReader(content), WithStrictParsing()); !errors.Is(err, ErrInvalidAliasName) {
+		t.Errorf("got error %v, wanted %v", err, ErrInvalidAliasName)
+	}
+}
@@ -0,0 +1,85 @@
+package gitlabcodeowners
+
+import (
+	"regexp"
+	"strings"
+)
+
+// annotationRegex matches a structured annotation like `@expires 2027-01-01`
+// within a doc comment.
+var annotationRegex = regexp.MustCompile(`^@([A-Za-z][\w-]*)(?:\s+(.*))?$`)
+
+// annotationKeys contains the keys of the supported annotations, so a
+// comment starting with an owner like `# @backend-team owns this` is
+// not mistaken for an annotation.
+var annotationKeys = map[string]bool{
+	"contact": true,
+	"expires": true,
+	"reason":  true,
+}
+
+// docComment returns the text of the comment block directly above the line
+// and the annotations found in it. The block ends at the first line above
+// which is not a comment, an empty line separates a comment from the line.
+// Lines with an annotation of `annotationKeys` are not part of the returned
+// text and neither are alias directives.
+func (s source) docComment() (string, map[string]string) {
+	var (
+		text        []string
+		annotations map[string]string
+	)
+
+	for _, line := range s.leading[commentBlockStart(s.leading):] {
+		if isAliasDirective(line) {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+
+		match := annotationRegex.FindStringSubmatch(line)
+		if match == nil || !annotationKeys[match[1]] {
+			text = append(text, line)
+
+			continue
+		}
+
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[match[1]] = strings.TrimSpace(match[2])
+	}
+
+	return strings.TrimSpace(strings.Join(text, "\n")), annotations
+}
+
+// commentBlockStart returns the index of the first line of the comments
+// directly above the line, which are considered to belong to the line itself.
+func commentBlockStart(leading []string) int {
+	start := len(leading)
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(leading[start-1]), "#") {
+		start--
+	}
+
+	return start
+}
+
+// mergeAnnotations returns the annotations of the section overridden by the
+// annotations of the rule, it returns nil if neither has annotations.
+func mergeAnnotations(sectionAnnotations, ruleAnnotations map[string]string) map[string]string {
+	if len(sectionAnnotations) == 0 {
+		return ruleAnnotations
+	}
+
+	merged := make(map[string]string, len(sectionAnnotations)+len(ruleAnnotations))
+
+	for key, value := range sectionAnnotations {
+		merged[key] = value
+	}
+
+	for key, value := range ruleAnnotations {
+		merged[key] = value
+	}
+
+	return merged
+}
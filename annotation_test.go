@@ -0,0 +1,124 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestAnnotation_docComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		leading         []string
+		wantText        string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "no leading lines",
+			leading:         nil,
+			wantText:        "",
+			wantAnnotations: nil,
+		},
+		{
+			name:            "comment separated by an empty line",
+			leading:         []string{"# unrelated", ""},
+			wantText:        "",
+			wantAnnotations: nil,
+		},
+		{
+			name:            "comment block",
+			leading:         []string{"# unrelated", "", "# Backend API", "#   owned since Q3"},
+			wantText:        "Backend API\nowned since Q3",
+			wantAnnotations: nil,
+		},
+		{
+			name: "annotations",
+			leading: []string{
+				"# Backend API",
+				"# @contact #team-backend-chat",
+				"  # @expires 2027-01-01",
+				"# @reason compliance review",
+				"# @contact",
+			},
+			wantText: "Backend API",
+			wantAnnotations: map[string]string{
+				"contact": "",
+				"expires": "2027-01-01",
+				"reason":  "compliance review",
+			},
+		},
+		{
+			name:            "owner is not an annotation",
+			leading:         []string{"# ask @alice", "# @ alice", "# @backend-team owns this", "# @flag"},
+			wantText:        "ask @alice\n@ alice\n@backend-team owns this\n@flag",
+			wantAnnotations: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			text, annotations := source{line: 1, raw: "*", leading: tt.leading}.docComment()
+
+			if text != tt.wantText {
+				t.Errorf("got %q, wanted %q", text, tt.wantText)
+			}
+
+			testhelper.DeepEqual(t, annotations, tt.wantAnnotations)
+		})
+	}
+}
+
+func TestAnnotation_approvals(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(`
+# Backend services
+# @contact #team-backend-chat
+# @reason compliance
+[Backend] @backend
+
+# Public API, keep stable
+# @reason contract with partners
+/api/ @api-team
+
+*.go
+`))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	got := file.GetRequiredApprovalsForFiles([]string{"/api/users.rb", "/cmd/main.go"})
+	want := map[string][]Approval{
+		"Backend": {
+			{
+				Pattern:    "/api/",
+				Approvals:  1,
+				Owners:     []string{"@api-team"},
+				Doc:        "Public API, keep stable",
+				SectionDoc: "Backend services",
+				Annotations: map[string]string{
+					"contact": "#team-backend-chat",
+					"reason":  "contract with partners",
+				},
+			},
+			{
+				Pattern:    "*.go",
+				Approvals:  1,
+				Owners:     []string{"@backend"},
+				SectionDoc: "Backend services",
+				Annotations: map[string]string{
+					"contact": "#team-backend-chat",
+					"reason":  "compliance",
+				},
+			},
+		},
+	}
+	testhelper.DeepEqual(t, got, want)
+}
@@ -0,0 +1,342 @@
+package gitlabcodeowners
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MembershipResolver resolves an owner to the individual people who can
+// approve for it.
+type MembershipResolver interface {
+	// ResolveOwner returns the people of a group or the owner itself if it
+	// is a single person.
+	ResolveOwner(owner string) []string
+}
+
+// ResolveOwner returns the members of a group including the members of its
+// subgroups, or the owner itself if it is not a group of the snapshot.
+// Groups are looked up case-insensitively like owners in Gitlab.
+func (m Membership) ResolveOwner(owner string) []string {
+	groups := m.groupsByKey()
+	people := []string{}
+	seen := map[string]bool{}
+
+	var resolve func(owner string)
+	resolve = func(owner string) {
+		key := strings.ToLower(owner)
+		if seen[key] {
+			return
+		}
+
+		seen[key] = true
+
+		members, ok := groups[key]
+		if !ok {
+			people = append(people, owner)
+
+			return
+		}
+
+		for _, member := range members {
+			resolve(member)
+		}
+	}
+
+	resolve(owner)
+
+	return people
+}
+
+// groupsByKey returns the members of the groups by the lowercase name of the
+// group, the members of groups whose names only differ in case are merged.
+func (m Membership) groupsByKey() map[string][]string {
+	names := make([]string, 0, len(m.Groups))
+	for name := range m.Groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	groups := make(map[string][]string, len(names))
+	for _, name := range names {
+		key := strings.ToLower(name)
+		groups[key] = append(groups[key], m.Groups[name]...)
+	}
+
+	return groups
+}
+
+// ApproverOption configures how `SuggestApprovers` chooses between people.
+type ApproverOption func(*approverConfig)
+
+type approverConfig struct {
+	resolver   MembershipResolver
+	preference map[string]int
+	load       map[string]float64
+}
+
+// WithMembershipResolver returns an option which expands groups to their
+// members. Without a resolver every owner is treated as a single person.
+func WithMembershipResolver(resolver MembershipResolver) ApproverOption {
+	return func(config *approverConfig) {
+		config.resolver = resolver
+	}
+}
+
+// WithPreference returns an option which prefers the given people in this
+// order, if several people cover the same number of approvals.
+func WithPreference(people ...string) ApproverOption {
+	return func(config *approverConfig) {
+		for _, person := range people {
+			if _, ok := config.preference[strings.ToLower(person)]; !ok {
+				config.preference[strings.ToLower(person)] = len(config.preference)
+			}
+		}
+	}
+}
+
+// WithLoad returns an option which weights each person by a load score like
+// the number of open reviews. A person with a lower load is preferred and
+// people without a score have a load of 1.
+func WithLoad(load map[string]float64) ApproverOption {
+	return func(config *approverConfig) {
+		for person, score := range load {
+			config.load[strings.ToLower(person)] = score
+		}
+	}
+}
+
+// ApproverSuggestion is the result of `SuggestApprovers`.
+type ApproverSuggestion struct {
+	// Approvers contains the suggested people in the order they were chosen.
+	Approvers []string
+
+	// Sections maps the name of each section to the suggested people who
+	// can approve for it.
+	Sections map[string][]string
+
+	// Unsatisfiable contains the names of the sections which have fewer
+	// eligible people than required approvals, sorted by name.
+	Unsatisfiable []string
+}
+
+// approvalRequirement is an approval which still needs approvals from
+// eligible people.
+type approvalRequirement struct {
+	section  string
+	eligible map[string]bool
+	needed   int
+}
+
+// SuggestApprovers returns a small set of people who together satisfy the
+// approval count of every approval, for example as returned by
+// `GetRequiredApprovalsForFiles`. Groups are expanded to their members by
+// the membership resolver and approvals of optional sections are ignored.
+// Use the `WithAuthorExcluded` option of the query to never suggest the
+// author together with `WithOwnerResolver` to remove them from groups.
+//
+// Finding the smallest set is a weighted set cover problem, so the people
+// are chosen greedily by the number of approvals they cover relative to
+// their load, and afterwards people who are not needed are removed again.
+func SuggestApprovers(approvals map[string][]Approval, options ...ApproverOption) ApproverSuggestion {
+	config := approverConfig{resolver: nil, preference: map[string]int{}, load: map[string]float64{}}
+	for _, option := range options {
+		option(&config)
+	}
+
+	requirements, names := config.requirements(approvals)
+	suggestion := ApproverSuggestion{Approvers: []string{}, Sections: map[string][]string{}, Unsatisfiable: []string{}}
+
+	for _, requirement := range requirements {
+		if len(requirement.eligible) >= requirement.needed {
+			continue
+		}
+
+		if !slices.Contains(suggestion.Unsatisfiable, requirement.section) {
+			suggestion.Unsatisfiable = append(suggestion.Unsatisfiable, requirement.section)
+		}
+
+		// cover the section with as many people as possible
+		requirement.needed = len(requirement.eligible)
+	}
+
+	sort.Strings(suggestion.Unsatisfiable)
+
+	chosen := config.chooseGreedily(requirements, names)
+	chosen = removeUnneeded(chosen, requirements)
+
+	for _, person := range chosen {
+		suggestion.Approvers = append(suggestion.Approvers, names[person])
+
+		for _, requirement := range requirements {
+			if requirement.eligible[person] && !slices.Contains(suggestion.Sections[requirement.section], names[person]) {
+				suggestion.Sections[requirement.section] = append(suggestion.Sections[requirement.section], names[person])
+			}
+		}
+	}
+
+	return suggestion
+}
+
+// requirements returns the approvals as requirements sorted by section and
+// the names of all eligible people by their lowercase key.
+func (c approverConfig) requirements(approvals map[string][]Approval) ([]*approvalRequirement, map[string]string) {
+	sections := make([]string, 0, len(approvals))
+	for section := range approvals {
+		sections = append(sections, section)
+	}
+
+	sort.Strings(sections)
+
+	requirements := []*approvalRequirement{}
+	names := map[string]string{}
+
+	for _, section := range sections {
+		for _, approval := range approvals[section] {
+			if approval.Approvals < 1 {
+				continue
+			}
+
+			requirement := &approvalRequirement{section: section, eligible: map[string]bool{}, needed: approval.Approvals}
+
+			for _, owner := range approval.Owners {
+				people := []string{owner}
+				if c.resolver != nil {
+					people = c.resolver.ResolveOwner(owner)
+				}
+
+				for _, person := range people {
+					key := strings.ToLower(person)
+					if _, ok := names[key]; !ok {
+						names[key] = person
+					}
+
+					requirement.eligible[key] = true
+				}
+			}
+
+			requirements = append(requirements, requirement)
+		}
+	}
+
+	return requirements, names
+}
+
+// chooseGreedily chooses the person with the best ratio of covered approvals
+// to load until all requirements are satisfied.
+func (c approverConfig) chooseGreedily(requirements []*approvalRequirement, names map[string]string) []string {
+	remaining := make([]int, len(requirements))
+	for i, requirement := range requirements {
+		remaining[i] = requirement.needed
+	}
+
+	candidates := make([]string, 0, len(names))
+	for person := range names {
+		candidates = append(candidates, person)
+	}
+
+	sort.Strings(candidates)
+
+	chosen := []string{}
+	picked := map[string]bool{}
+
+	for {
+		best, bestScore := "", 0.0
+
+		for _, person := range candidates {
+			if picked[person] {
+				continue
+			}
+
+			covered := 0
+
+			for i, requirement := range requirements {
+				if remaining[i] > 0 && requirement.eligible[person] {
+					covered++
+				}
+			}
+
+			if covered == 0 {
+				continue
+			}
+
+			score := float64(covered) / c.loadOf(person)
+			if best == "" || score > bestScore || score == bestScore && c.prefers(person, best) {
+				best, bestScore = person, score
+			}
+		}
+
+		if best == "" {
+			return chosen
+		}
+
+		picked[best] = true
+		chosen = append(chosen, best)
+
+		for i, requirement := range requirements {
+			if remaining[i] > 0 && requirement.eligible[best] {
+				remaining[i]--
+			}
+		}
+	}
+}
+
+// loadOf returns the load of the person, it is at least a small positive
+// number so people without load are preferred without dividing by zero.
+func (c approverConfig) loadOf(person string) float64 {
+	load, ok := c.load[person]
+	if !ok {
+		return 1
+	}
+
+	return max(load, 1e-9) //nolint:gomnd // avoid a division by zero
+}
+
+// prefers reports if the person is preferred over the other person, which
+// covers the same number of approvals.
+func (c approverConfig) prefers(person, other string) bool {
+	rank, ok := c.preference[person]
+	otherRank, otherOK := c.preference[other]
+
+	switch {
+	case ok && otherOK:
+		return rank < otherRank
+	case ok != otherOK:
+		return ok
+	}
+
+	return c.loadOf(person) < c.loadOf(other)
+}
+
+// removeUnneeded removes the people chosen last, whose approvals are not
+// needed to satisfy the requirements.
+func removeUnneeded(chosen []string, requirements []*approvalRequirement) []string {
+	for i := len(chosen) - 1; i >= 0; i-- {
+		without := append(append([]string{}, chosen[:i]...), chosen[i+1:]...)
+
+		if satisfies(without, requirements) {
+			chosen = without
+		}
+	}
+
+	return chosen
+}
+
+func satisfies(people []string, requirements []*approvalRequirement) bool {
+	for _, requirement := range requirements {
+		count := 0
+
+		for _, person := range people {
+			if requirement.eligible[person] {
+				count++
+			}
+		}
+
+		if count < requirement.needed {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,115 @@
+package gitlabcodeowners
+
+import (
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestApprovers_SuggestApprovers(t *testing.T) {
+	t.Parallel()
+
+	approvals := map[string][]Approval{
+		"Docs":     {{Pattern: "docs/", Approvals: 2, Owners: []string{"@alice", "@bob", "@carol"}}},
+		"Code":     {{Pattern: "*.go", Approvals: 1, Owners: []string{"@Bob", "@dave"}}},
+		"Optional": {{Pattern: "*", Approvals: 0, Owners: []string{"@erin"}}},
+	}
+
+	tests := []struct {
+		name      string
+		approvals map[string][]Approval
+		options   []ApproverOption
+		want      ApproverSuggestion
+	}{
+		{
+			name:      "no approvals",
+			approvals: map[string][]Approval{},
+			want:      ApproverSuggestion{Approvers: []string{}, Sections: map[string][]string{}, Unsatisfiable: []string{}},
+		},
+		{
+			name:      "cover several sections",
+			approvals: approvals,
+			want: ApproverSuggestion{
+				Approvers:     []string{"@Bob", "@alice"},
+				Sections:      map[string][]string{"Code": {"@Bob"}, "Docs": {"@Bob", "@alice"}},
+				Unsatisfiable: []string{},
+			},
+		},
+		{
+			name:      "preference breaks ties",
+			approvals: approvals,
+			options:   []ApproverOption{WithPreference("@dave", "@CAROL")},
+			want: ApproverSuggestion{
+				Approvers:     []string{"@Bob", "@carol"},
+				Sections:      map[string][]string{"Code": {"@Bob"}, "Docs": {"@Bob", "@carol"}},
+				Unsatisfiable: []string{},
+			},
+		},
+		{
+			name:      "load avoids busy people",
+			approvals: approvals,
+			options:   []ApproverOption{WithLoad(map[string]float64{"@bob": 10})},
+			want: ApproverSuggestion{
+				Approvers:     []string{"@alice", "@carol", "@dave"},
+				Sections:      map[string][]string{"Code": {"@dave"}, "Docs": {"@alice", "@carol"}},
+				Unsatisfiable: []string{},
+			},
+		},
+		{
+			name: "groups are expanded",
+			approvals: map[string][]Approval{
+				"Docs": {{Pattern: "docs/", Approvals: 2, Owners: []string{"@org/docs"}}},
+				"Code": {
+					{Pattern: "*.go", Approvals: 1, Owners: []string{"@carol"}},
+					{Pattern: "/cmd/", Approvals: 1, Owners: []string{"@org/all"}},
+				},
+			},
+			options: []ApproverOption{WithMembershipResolver(Membership{
+				Users:  nil,
+				Groups: map[string][]string{"@org/docs": {"@alice", "@bob"}, "@org/all": {"@org/docs", "@carol"}},
+			})},
+			want: ApproverSuggestion{
+				Approvers:     []string{"@alice", "@bob", "@carol"},
+				Sections:      map[string][]string{"Code": {"@alice", "@bob", "@carol"}, "Docs": {"@alice", "@bob"}},
+				Unsatisfiable: []string{},
+			},
+		},
+		{
+			name: "unsatisfiable section",
+			approvals: map[string][]Approval{
+				"Security": {{Pattern: "*", Approvals: 3, Owners: []string{"@eve", "@frank"}}},
+				"Docs":     {{Pattern: "*", Approvals: 1, Owners: []string{"@eve"}}},
+			},
+			want: ApproverSuggestion{
+				Approvers:     []string{"@eve", "@frank"},
+				Sections:      map[string][]string{"Docs": {"@eve"}, "Security": {"@eve", "@frank"}},
+				Unsatisfiable: []string{"Security"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testhelper.DeepEqual(t, SuggestApprovers(tt.approvals, tt.options...), tt.want)
+		})
+	}
+}
+
+func TestApprovers_ResolveOwner(t *testing.T) {
+	t.Parallel()
+
+	membership := Membership{
+		Users:  []string{"@alice"},
+		Groups: map[string][]string{"@a": {"@alice", "@b"}, "@b": {"@bob", "@a"}},
+	}
+
+	testhelper.DeepEqual(t, membership.ResolveOwner("@a"), []string{"@alice", "@bob"})
+	testhelper.DeepEqual(t, membership.ResolveOwner("@carol"), []string{"@carol"})
+
+	membership.Groups = map[string][]string{"@Org/Docs": {"@alice", "@org/writers"}, "@org/Writers": {"@Alice", "@bob"}}
+	testhelper.DeepEqual(t, membership.ResolveOwner("@org/docs"), []string{"@alice", "@bob"})
+}
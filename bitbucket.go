@@ -0,0 +1,135 @@
+package gitlabcodeowners
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SelectionStrategy describes how reviewers are picked from a reviewer group.
+type SelectionStrategy string
+
+const (
+	// SelectionRandom picks random reviewers from the owners.
+	SelectionRandom SelectionStrategy = "Random"
+
+	// SelectionLeastBusy picks the reviewers with the fewest open reviews.
+	SelectionLeastBusy SelectionStrategy = "LeastBusy"
+)
+
+// ReviewerSelection describes a reviewer selection directive of a rule,
+// for example `Random(@@team, 2)` in a Bitbucket `CODEOWNERS` file.
+type ReviewerSelection struct {
+	Strategy SelectionStrategy
+	Count    int
+	Owners   []string
+}
+
+var selectionDirectiveRegex = regexp.MustCompile(`^(Random|LeastBusy)\((.*)\)$`)
+
+func parseBitbucketFile(reader io.Reader, config parseConfig) (File, error) {
+	return parseRulesFile(reader, config, func(line string) (rule, bool, error) {
+		line = strings.TrimSpace(line)
+
+		// skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			return rule{}, false, nil
+		}
+
+		rule, err := parseBitbucketRule(line)
+
+		return rule, err == nil, err
+	})
+}
+
+func parseBitbucketRule(line string) (rule, error) {
+	tokens := tokenizeBitbucketLine(line)
+	if len(tokens) == 0 {
+		return rule{}, ErrEmptyRule
+	}
+
+	owners := []string{}
+
+	var selections []ReviewerSelection
+
+	for _, token := range tokens[1:] {
+		selection, ok := parseSelectionDirective(token)
+		if !ok {
+			owners = append(owners, token)
+
+			continue
+		}
+
+		owners = append(owners, selection.Owners...)
+		selections = append(selections, selection)
+	}
+
+	pattern, err := parsePattern(tokens[0])
+	if err != nil {
+		return rule{}, err
+	}
+
+	rule := newRule(pattern, owners)
+	rule.selections = selections
+
+	return rule, nil
+}
+
+// tokenizeBitbucketLine splits a line into whitespace separated tokens,
+// but keeps whitespace within the parentheses of a directive.
+func tokenizeBitbucketLine(line string) []string {
+	tokens := []string{}
+	token := strings.Builder{}
+	depth := 0
+
+	for _, char := range line {
+		switch {
+		case char == '(':
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+		case unicode.IsSpace(char) && depth == 0:
+			tokens = appendToken(tokens, token.String())
+			token.Reset()
+
+			continue
+		}
+
+		token.WriteRune(char)
+	}
+
+	return appendToken(tokens, token.String())
+}
+
+func parseSelectionDirective(token string) (ReviewerSelection, bool) {
+	match := selectionDirectiveRegex.FindStringSubmatch(token)
+	if match == nil {
+		return ReviewerSelection{}, false
+	}
+
+	selection := ReviewerSelection{
+		Strategy: SelectionStrategy(match[1]),
+		Count:    1,
+		Owners:   []string{},
+	}
+
+	args := strings.FieldsFunc(match[2], func(c rune) bool {
+		return c == ',' || unicode.IsSpace(c)
+	})
+
+	for _, arg := range args {
+		if count, err := strconv.Atoi(arg); err == nil {
+			if count > 0 {
+				selection.Count = count
+			}
+
+			continue
+		}
+
+		selection.Owners = append(selection.Owners, arg)
+	}
+
+	return selection, true
+}
@@ -0,0 +1,57 @@
+package gitlabcodeowners
+
+// Builder creates a `File` section by section and rule by rule.
+// Rules which are added before the first section belong to the
+// default section without a name.
+type Builder struct {
+	sections []section
+}
+
+// NewBuilder returns a builder for an empty `File`.
+func NewBuilder() *Builder {
+	return &Builder{
+		sections: []section{newSection("", 1, false, []string{})},
+	}
+}
+
+// Section starts a new required section with the given approval count and
+// default owners. All following rules are added to this section.
+func (b *Builder) Section(name string, approvals int, owners ...string) *Builder {
+	if approvals < 1 {
+		approvals = 1
+	}
+
+	b.sections = append(b.sections, newSection(name, approvals, false, append([]string{}, owners...)))
+
+	return b
+}
+
+// OptionalSection starts a new optional section with the given default
+// owners. All following rules are added to this section.
+func (b *Builder) OptionalSection(name string, owners ...string) *Builder {
+	b.sections = append(b.sections, newSection(name, 0, true, append([]string{}, owners...)))
+
+	return b
+}
+
+// Rule adds a rule with the given pattern and owners to the current section.
+func (b *Builder) Rule(pattern string, owners ...string) *Builder {
+	current := &b.sections[len(b.sections)-1]
+	current.rules = append(current.rules, newRule(newPattern(pattern), append([]string{}, owners...)))
+
+	return b
+}
+
+// Build returns the `File` with all added sections and rules. Sections
+// without rules are dropped and sections with the same name are merged
+// the same way as when a file is parsed.
+func (b *Builder) Build() File {
+	sections := []section{}
+
+	for _, sec := range b.sections {
+		sec.rules = append([]rule{}, sec.rules...)
+		sections = appendSection(sections, sec)
+	}
+
+	return File{sections: sections, trailing: nil, parseErrors: nil, byteOrderMark: false, crlf: false}
+}
@@ -0,0 +1,34 @@
+package gitlabcodeowners
+
+import (
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	file := NewBuilder().
+		Rule("*", "@general-approvers").
+		Section("Documentation", 2, "@docs-team").
+		Rule("docs/").
+		Section("Empty", 1).
+		OptionalSection("Database", "@database-team").
+		Rule("model/db/").
+		Section("documentation", 1).
+		Rule("README.md", "@writers").
+		Build()
+
+	want := "* @general-approvers\n\n[Documentation][2] @docs-team\ndocs/\nREADME.md @writers\n\n^[Database] @database-team\nmodel/db/\n"
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	got := file.GetRequiredApprovalsForFile("/model/db/README.md")
+	testhelper.DeepEqual(t, got, map[string]Approval{
+		"":              {Pattern: "*", Approvals: 1, Owners: []string{"@general-approvers"}},
+		"Documentation": {Pattern: "README.md", Approvals: 2, Owners: []string{"@writers"}},
+		"Database":      {Pattern: "model/db/", Approvals: 0, Owners: []string{"@database-team"}},
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+// generatedNotice is written at the beginning of a composed file.
+const generatedNotice = "# This file is generated by `gitlabcodeowners compose`, do not edit it.\n\n"
+
+func runCompose(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("compose", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners compose [-pattern <glob>] [-o <file> [-check]] [directory]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Composes the fragment files below the directory into one CODEOWNERS file,")
+		fmt.Fprintln(stderr, "where the patterns of each fragment are rooted at its directory.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	pattern := flags.String("pattern", gitlabcodeowners.DefaultFragmentPattern, "glob matching the fragment files")
+	output := flags.String("o", "", "file to write the result to instead of stdout")
+	check := flags.Bool("check", false, "only check that the output file is up to date")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() > 1 || (*check && *output == "") {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	directory := "."
+	if flags.NArg() == 1 {
+		directory = flags.Arg(0)
+	}
+
+	file, err := gitlabcodeowners.ComposeFragments(os.DirFS(directory), *pattern)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	content := []byte(generatedNotice + file.String())
+
+	switch {
+	case *check:
+		return checkComposed(*output, content, stderr)
+	case *output == "":
+		_, _ = stdout.Write(content)
+	default:
+		if err := os.WriteFile(*output, content, 0o644); err != nil { //nolint:gosec,gomnd // keep default permissions
+			fmt.Fprintf(stderr, "failed to write '%s': %v\n", *output, err)
+
+			return exitFailure
+		}
+	}
+
+	return exitSuccess
+}
+
+func checkComposed(path string, content []byte, stderr io.Writer) int {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read '%s': %v\n", path, err)
+
+		return exitFailure
+	}
+
+	if !bytes.Equal(existing, content) {
+		fmt.Fprintf(stderr, "'%s' is stale, run `gitlabcodeowners compose -o %s` to update it\n", path, path)
+
+		return exitFailure
+	}
+
+	return exitSuccess
+}
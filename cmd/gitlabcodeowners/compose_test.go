@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompose_runCompose(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	output := filepath.Join(root, "CODEOWNERS")
+
+	writeTestFile(t, filepath.Join(root, "services", "api", "CODEOWNERS.fragment"), "/v1/ @api\n")
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"compose", "-o", output, "-check", root}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d for a missing file, wanted %d", status, exitFailure)
+	}
+
+	if status := run([]string{"compose", "-o", output, root}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Fatalf("got status %d, stderr %s", status, stderr.String())
+	}
+
+	if got, want := readTestFile(t, output), generatedNotice+"/services/api/v1/ @api\n"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if status := run([]string{"compose", "-o", output, "-check", root}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d for an up to date file, stderr %s", status, stderr.String())
+	}
+
+	writeTestFile(t, filepath.Join(root, "services", "web", "CODEOWNERS.fragment"), "* @web\n")
+	stderr.Reset()
+
+	if status := run([]string{"compose", "-o", output, "-check", root}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d for a stale file, wanted %d", status, exitFailure)
+	}
+
+	if !strings.Contains(stderr.String(), "is stale") {
+		t.Errorf("stderr %q does not report the stale file", stderr.String())
+	}
+
+	if status := run([]string{"compose", "-check", root}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d without output file, wanted %d", status, exitUsage)
+	}
+}
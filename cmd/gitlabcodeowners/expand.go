@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runExpand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("expand", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners expand [-o <file>] <file>")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Replaces the aliases defined with `# alias @name = <owners>`")
+		fmt.Fprintln(stderr, "by their owners, so the file can be used by Gitlab.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	output := flags.String("o", "", "file to write the result to instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	file, err := readCodeOwnersFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	file.ExpandAliases()
+
+	if *output == "" {
+		fmt.Fprint(stdout, file.String())
+
+		return exitSuccess
+	}
+
+	if err := os.WriteFile(*output, []byte(file.String()), 0o644); err != nil { //nolint:gosec,gomnd // keep default permissions
+		fmt.Fprintf(stderr, "failed to write '%s': %v\n", *output, err)
+
+		return exitFailure
+	}
+
+	return exitSuccess
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpand_runExpand(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	input := filepath.Join(root, "CODEOWNERS.in")
+	output := filepath.Join(root, "CODEOWNERS")
+	want := "* @alice @bob\n"
+
+	writeTestFile(t, input, "# alias @team = @alice @bob\n* @team\n")
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"expand", input}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Fatalf("got status %d, stderr %s", status, stderr.String())
+	}
+
+	if got := stdout.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if status := run([]string{"expand", "-o", output, input}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Fatalf("got status %d, stderr %s", status, stderr.String())
+	}
+
+	if got := readTestFile(t, output); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if status := run([]string{"expand"}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d, wanted %d", status, exitUsage)
+	}
+
+	if status := run([]string{"expand", filepath.Join(root, "missing")}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+}
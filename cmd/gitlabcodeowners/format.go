@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+const (
+	formatText        = "text"
+	formatCodeQuality = "codequality"
+	formatSARIF       = "sarif"
+)
+
+var (
+	errUnknownFormat = errors.New("unknown output format")
+	errOutsideOfRoot = errors.New("file is not below the root directory")
+)
+
+// fileDiagnostics contains the diagnostics found in the file at the path.
+type fileDiagnostics struct {
+	path        string
+	diagnostics []gitlabcodeowners.Diagnostic
+}
+
+// failures returns the number of diagnostics which fail a command. Info
+// diagnostics are only reported and never fail a command.
+func failures(diagnostics []gitlabcodeowners.Diagnostic) int {
+	count := 0
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity != gitlabcodeowners.SeverityInfo {
+			count++
+		}
+	}
+
+	return count
+}
+
+func checkFormat(format string) error {
+	if format != formatText && format != formatCodeQuality && format != formatSARIF {
+		return fmt.Errorf("%w '%s', use '%s', '%s' or '%s'", errUnknownFormat, format, formatText, formatCodeQuality, formatSARIF)
+	}
+
+	return nil
+}
+
+// writeDiagnostics writes the diagnostics of all files in the given format
+// and returns the number of failures. The checks describe the rules of a
+// SARIF report and the paths in the reports are relative to the root
+// directory.
+func writeDiagnostics(
+	writer io.Writer, format, root string, checks []gitlabcodeowners.Check, results []fileDiagnostics,
+) (int, error) {
+	count := 0
+
+	for _, result := range results {
+		count += failures(result.diagnostics)
+	}
+
+	if format != formatText {
+		for i := range results {
+			path, err := relativePath(root, results[i].path)
+			if err != nil {
+				return count, err
+			}
+
+			results[i].path = path
+		}
+	}
+
+	switch format {
+	case formatCodeQuality:
+		issues := []gitlabcodeowners.CodeQualityIssue{}
+
+		for _, result := range results {
+			issues = append(issues, gitlabcodeowners.CodeQualityIssues(result.path, result.diagnostics)...)
+		}
+
+		if err := gitlabcodeowners.WriteCodeQualityReport(writer, issues); err != nil {
+			return count, fmt.Errorf("failed to write report: %w", err)
+		}
+	case formatSARIF:
+		report := gitlabcodeowners.NewSARIFReport(checks...)
+
+		for _, result := range results {
+			report.Add(result.path, result.diagnostics)
+		}
+
+		if err := report.Write(writer); err != nil {
+			return count, fmt.Errorf("failed to write report: %w", err)
+		}
+	default:
+		for _, result := range results {
+			printDiagnostics(writer, result.path, result.diagnostics)
+		}
+	}
+
+	return count, nil
+}
+
+// relativePath returns the path relative to the root directory, which is the
+// current directory if it is empty.
+func relativePath(root, path string) (string, error) {
+	if root == "" {
+		root = "."
+	}
+
+	absoluteRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s': %w", root, err)
+	}
+
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s': %w", path, err)
+	}
+
+	relative, err := filepath.Rel(absoluteRoot, absolutePath)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: '%s' is not below '%s'", errOutsideOfRoot, path, root)
+	}
+
+	return relative, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+func runLint(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners lint [-config <file>] [-format <format>] [-root <directory>] [-list] [file...]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Reports problems in the given CODEOWNERS files, by default the first")
+		fmt.Fprintln(stderr, "file found at one of the locations supported by Gitlab is checked.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	configPath := flags.String("config", "", "JSON file which enables and disables checks")
+	format := flags.String("format", formatText, "output format, 'text', 'codequality' for a Gitlab Code Quality report or 'sarif'")
+	root := flags.String("root", "", "root directory of the repository, the paths in reports are relative to it (default: current directory)")
+	list := flags.Bool("list", false, "list the available checks")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if err := checkFormat(*format); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	if *list {
+		for _, check := range gitlabcodeowners.DefaultChecks() {
+			fmt.Fprintf(stdout, "%-32s %-8s %-8t %s\n", check.Name, check.Severity, check.Enabled, check.Doc)
+		}
+
+		return exitSuccess
+	}
+
+	linter, err := newLinter(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = defaultCodeOwnersFile()
+	}
+
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "no CODEOWNERS file found")
+
+		return exitFailure
+	}
+
+	status := exitSuccess
+	results := []fileDiagnostics{}
+
+	for _, path := range paths {
+		file, err := readCodeOwnersFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+
+			status = exitFailure
+
+			continue
+		}
+
+		results = append(results, fileDiagnostics{path: path, diagnostics: linter.Lint(file)})
+	}
+
+	count, err := writeDiagnostics(stdout, *format, *root, gitlabcodeowners.DefaultChecks(), results)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	if count > 0 {
+		status = exitFailure
+	}
+
+	return status
+}
+
+func newLinter(configPath string) (*gitlabcodeowners.Linter, error) {
+	config := gitlabcodeowners.LintConfig{DisableAll: false, Enable: nil, Disable: nil, Severity: nil}
+
+	if configPath != "" {
+		reader, err := os.Open(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open '%s': %w", configPath, err)
+		}
+		defer reader.Close()
+
+		config, err = gitlabcodeowners.ReadLintConfig(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", configPath, err)
+		}
+	}
+
+	linter, err := gitlabcodeowners.NewLinter(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure linter: %w", err)
+	}
+
+	return linter, nil
+}
+
+// printDiagnostics prints the diagnostics for the file at the given path.
+func printDiagnostics(writer io.Writer, path string, diagnostics []gitlabcodeowners.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintf(
+			writer, "%s:%d:%d: %s: %s (%s)\n", path, diagnostic.Line, diagnostic.Column,
+			diagnostic.Severity, diagnostic.Message, diagnostic.Check,
+		)
+	}
+}
+
+// defaultCodeOwnersFile returns the first `CODEOWNERS` file in the current
+// directory which is found at a location supported by Gitlab.
+func defaultCodeOwnersFile() []string {
+	for _, location := range gitlabcodeowners.GetPossibleCodeOwnersLocations() {
+		path := strings.TrimPrefix(location, "/")
+
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}
+		}
+	}
+
+	return nil
+}
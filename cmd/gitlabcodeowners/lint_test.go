@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint_runLint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	path := filepath.Join(root, "CODEOWNERS")
+	config := filepath.Join(root, "lint.json")
+
+	writeTestFile(t, path, "[Docs] docs-team\n/docs/\n*.md @a\n")
+	writeTestFile(t, config, `{"enable": ["unsorted-rules"], "severity": {"invalid-owner": "warning"}}`)
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"lint", "-config", config, path}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	want := path + ":1:8: warning: owner 'docs-team' is neither prefixed with '@' nor a valid email address (invalid-owner)\n" +
+		path + ":3:0: info: rule '*.md' should be sorted before '/docs/' (unsorted-rules)\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	stdout.Reset()
+
+	if status := run([]string{"lint", "-format", "codequality", "-root", root, path}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	for _, want := range []string{`"check_name": "invalid-owner"`, `"path": "CODEOWNERS"`, `"begin": 1`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("code quality report %s does not contain %s", stdout.String(), want)
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	args := []string{"lint", "-format", "sarif", "-root", filepath.Join(root, "docs"), path}
+	if status := run(args, nil, &stdout, &stderr); status != exitFailure || !strings.Contains(stderr.String(), "not below the root directory") {
+		t.Errorf("got status %d and stderr %q for a file outside of the root directory", status, stderr.String())
+	}
+
+	if status := run([]string{"lint", "-format", "xml", path}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d for an unknown format, wanted %d", status, exitUsage)
+	}
+
+	writeTestFile(t, path, "*.md @a\n")
+	stdout.Reset()
+
+	if status := run([]string{"lint", path}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d, stdout %s", status, stdout.String())
+	}
+
+	writeTestFile(t, path, "/docs/ @a\n*.md @a\n")
+	writeTestFile(t, config, `{"enable": ["unsorted-rules"]}`)
+	stdout.Reset()
+
+	if status := run([]string{"lint", "-config", config, path}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d for info diagnostics, wanted %d", status, exitSuccess)
+	}
+
+	want = path + ":2:0: info: rule '*.md' should be sorted before '/docs/' (unsorted-rules)\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	writeTestFile(t, config, `{"enable": ["unknown"]}`)
+
+	if status := run([]string{"lint", "-config", config, path}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d for an unknown check, wanted %d", status, exitFailure)
+	}
+
+	stdout.Reset()
+
+	if status := run([]string{"lint", "-list"}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d, wanted %d", status, exitSuccess)
+	}
+
+	if !strings.Contains(stdout.String(), "unsorted-rules") {
+		t.Errorf("stdout %q does not list the checks", stdout.String())
+	}
+}
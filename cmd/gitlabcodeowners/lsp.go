@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+func runLSP(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners lsp [-config <file>] [-membership <file>]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Runs a language server for CODEOWNERS files which communicates")
+		fmt.Fprintln(stderr, "over stdin and stdout.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	configPath := flags.String("config", "", "JSON file which enables and disables checks")
+	membershipPath := flags.String("membership", "", "JSON snapshot of the users and groups to complete owners")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	linter, err := newLinter(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	options := []gitlabcodeowners.LanguageServerOption{gitlabcodeowners.WithLinter(linter)}
+
+	if *membershipPath != "" {
+		membership, err := readMembership(*membershipPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+
+			return exitFailure
+		}
+
+		options = append(options, gitlabcodeowners.WithMembership(membership))
+	}
+
+	if err := gitlabcodeowners.NewLanguageServer(options...).Serve(stdin, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+func readMembership(path string) (gitlabcodeowners.Membership, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return gitlabcodeowners.Membership{}, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer reader.Close()
+
+	membership, err := gitlabcodeowners.ReadMembership(reader)
+	if err != nil {
+		return gitlabcodeowners.Membership{}, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	return membership, nil
+}
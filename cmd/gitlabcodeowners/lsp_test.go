@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLSP_runLSP(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	membership := filepath.Join(root, "membership.json")
+
+	writeTestFile(t, membership, `{"members": ["@alice"]}`)
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"lsp", "-membership", membership}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d for an invalid membership, wanted %d", status, exitFailure)
+	}
+
+	if !strings.Contains(stderr.String(), "invalid membership") {
+		t.Errorf("stderr %q does not report the invalid membership", stderr.String())
+	}
+
+	if status := run([]string{"lsp", "CODEOWNERS"}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d for an argument, wanted %d", status, exitUsage)
+	}
+
+	stdout.Reset()
+
+	stdin := strings.NewReader("Content-Length: 46\r\n\r\n" + `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	if status := run([]string{"lsp"}, stdin, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d, wanted %d", status, exitSuccess)
+	}
+
+	if !strings.Contains(stdout.String(), `"id":1`) {
+		t.Errorf("stdout %q does not contain the response to the request", stdout.String())
+	}
+}
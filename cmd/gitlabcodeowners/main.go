@@ -0,0 +1,97 @@
+// Command gitlabcodeowners provides command line tools
+// to work with `CODEOWNERS` files from Gitlab.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+func commands() map[string]command {
+	return map[string]command{
+		"compose": {
+			usage: "compose a CODEOWNERS file from the fragments below a directory",
+			run:   runCompose,
+		},
+		"expand": {
+			usage: "replace the aliases in a CODEOWNERS file by their owners",
+			run:   runExpand,
+		},
+		"lint": {
+			usage: "report problems in CODEOWNERS files",
+			run:   runLint,
+		},
+		"lsp": {
+			usage: "run a language server for CODEOWNERS files",
+			run:   runLSP,
+		},
+		"match": {
+			usage: "test if the pattern of a rule matches the given paths",
+			run:   runMatch,
+		},
+		"migrate": {
+			usage: "rewrite owners in all CODEOWNERS files below the given directories",
+			run:   runMigrate,
+		},
+		"policy": {
+			usage: "check a CODEOWNERS file against a policy",
+			run:   runPolicy,
+		},
+		"reviewers": {
+			usage: "choose reviewers for the changed paths",
+			run:   runReviewers,
+		},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+
+		return exitUsage
+	}
+
+	cmd, found := commands()[args[0]]
+	if !found {
+		fmt.Fprintf(stderr, "unknown command '%s'\n", args[0])
+		printUsage(stderr)
+
+		return exitUsage
+	}
+
+	return cmd.run(args[1:], stdin, stdout, stderr)
+}
+
+func printUsage(writer io.Writer) {
+	fmt.Fprintln(writer, "usage: gitlabcodeowners <command> [arguments]")
+	fmt.Fprintln(writer, "")
+	fmt.Fprintln(writer, "commands:")
+
+	names := []string{}
+	for name := range commands() {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(writer, "  %-10s %s\n", name, commands()[name].usage)
+	}
+}
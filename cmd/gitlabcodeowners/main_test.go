@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMain_run(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantStderr string
+	}{
+		{
+			name:       "no command",
+			args:       []string{},
+			wantStatus: exitUsage,
+			wantStderr: "usage: gitlabcodeowners <command> [arguments]",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"unknown"},
+			wantStatus: exitUsage,
+			wantStderr: "unknown command 'unknown'",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+			if got := run(tt.args, nil, &stdout, &stderr); got != tt.wantStatus {
+				t.Errorf("got status %d, wanted %d", got, tt.wantStatus)
+			}
+
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+func runMatch(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("match", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners match [-i] <pattern> <path...>")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Reports if the pattern of a rule matches the given paths, which are")
+		fmt.Fprintln(stderr, "relative to the root of the repository. Exits with a failure status")
+		fmt.Fprintln(stderr, "unless all paths match.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	caseInsensitive := flags.Bool("i", false, "match the paths case-insensitively")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() < 2 { //nolint:gomnd // pattern and at least one path
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	pattern := gitlabcodeowners.NewPattern(flags.Arg(0))
+	options := []gitlabcodeowners.QueryOption{gitlabcodeowners.WithPathNormalization()}
+
+	if *caseInsensitive {
+		options = append(options, gitlabcodeowners.WithCaseInsensitiveMatching())
+	}
+
+	fmt.Fprintf(stdout, "pattern '%s' is normalized to '%s'\n", pattern.Value(), pattern.Normalized())
+
+	status := exitSuccess
+
+	for _, path := range flags.Args()[1:] {
+		result := "matches"
+		if !pattern.Match(path, options...) {
+			result = "does not match"
+			status = exitFailure
+		}
+
+		fmt.Fprintf(stdout, "%s: %s\n", path, result)
+	}
+
+	return status
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMatch_runMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantStdout string
+	}{
+		{
+			name:       "all paths match",
+			args:       []string{"docs/", "docs/README.md", "/src/docs/api.md"},
+			wantStatus: exitSuccess,
+			wantStdout: "pattern 'docs/' is normalized to '/**/docs/**/*'\ndocs/README.md: matches\n/src/docs/api.md: matches\n",
+		},
+		{
+			name:       "path does not match",
+			args:       []string{"/docs/*.md", "docs/README.md", "docs/api/intro.md"},
+			wantStatus: exitFailure,
+			wantStdout: "pattern '/docs/*.md' is normalized to '/docs/*.md'\ndocs/README.md: matches\ndocs/api/intro.md: does not match\n",
+		},
+		{
+			name:       "case-insensitive",
+			args:       []string{"-i", "*.md", "README.MD"},
+			wantStatus: exitSuccess,
+			wantStdout: "pattern '*.md' is normalized to '/**/*.md'\nREADME.MD: matches\n",
+		},
+		{
+			name:       "missing path",
+			args:       []string{"*.md"},
+			wantStatus: exitUsage,
+			wantStdout: "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+			if status := run(append([]string{"match"}, tt.args...), nil, &stdout, &stderr); status != tt.wantStatus {
+				t.Errorf("got status %d, wanted %d", status, tt.wantStatus)
+			}
+
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("got %q, wanted %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
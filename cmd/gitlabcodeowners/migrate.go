@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+var errInvalidMapping = errors.New("invalid mapping")
+
+func runMigrate(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners migrate -mapping <file> [-dry-run] [directory...]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "The mapping file contains one `<old> <new>` pair per line,")
+		fmt.Fprintln(stderr, "an owner without a new owner is removed.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	mappingPath := flags.String("mapping", "", "file with the owner mapping")
+	dryRun := flags.Bool("dry-run", false, "only report the changes without writing the files")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *mappingPath == "" {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	mapping, err := readMapping(*mappingPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	directories := flags.Args()
+	if len(directories) == 0 {
+		directories = []string{"."}
+	}
+
+	status := exitSuccess
+
+	for _, directory := range directories {
+		paths, err := findCodeOwnersFiles(directory)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+
+			status = exitFailure
+
+			continue
+		}
+
+		for _, path := range paths {
+			if err := migrateFile(path, mapping, *dryRun, stdout); err != nil {
+				fmt.Fprintln(stderr, err)
+
+				status = exitFailure
+			}
+		}
+	}
+
+	return status
+}
+
+func migrateFile(path string, mapping map[string]string, dryRun bool, stdout io.Writer) error {
+	file, err := readCodeOwnersFile(path)
+	if err != nil {
+		return err
+	}
+
+	result := file.MigrateOwners(mapping)
+
+	fmt.Fprintf(stdout, "%s: replaced %d, removed %d owners\n", path, result.Replaced, result.Removed)
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(
+			stdout, "%s:%d: warning: rule '%s' in section '%s' has no owners anymore\n",
+			path, warning.Line, warning.Pattern, warning.Section,
+		)
+	}
+
+	if dryRun || result.Replaced+result.Removed == 0 {
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(file.String()), 0o644); err != nil { //nolint:gosec,gomnd // keep default permissions
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+func readCodeOwnersFile(path string) (gitlabcodeowners.File, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return gitlabcodeowners.File{}, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer reader.Close()
+
+	file, err := gitlabcodeowners.NewCodeOwnersFile(reader)
+	if err != nil {
+		return gitlabcodeowners.File{}, fmt.Errorf("failed to parse '%s': %w", path, err)
+	}
+
+	return file, nil
+}
+
+// findCodeOwnersFiles returns all `CODEOWNERS` files below the given
+// directory, which can contain many repositories.
+func findCodeOwnersFiles(root string) ([]string, error) {
+	paths := []string{}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if !entry.IsDir() && entry.Name() == "CODEOWNERS" {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to search '%s': %w", root, err)
+	}
+
+	return paths, nil
+}
+
+func readMapping(path string) (map[string]string, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer reader.Close()
+
+	mapping := map[string]string{}
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lineNumber++
+
+		fields := strings.Fields(scanner.Text())
+
+		switch {
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "#"):
+			continue
+		case len(fields) == 1:
+			mapping[fields[0]] = ""
+		case len(fields) == 2: //nolint:gomnd // old and new owner
+			mapping[fields[0]] = fields[1]
+		default:
+			return map[string]string{}, fmt.Errorf("%w in '%s' on line %d", errInvalidMapping, path, lineNumber)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return map[string]string{}, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	return string(content)
+}
+
+func TestMigrate_runMigrate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mapping := filepath.Join(root, "mapping.txt")
+	first := filepath.Join(root, "first", "CODEOWNERS")
+	second := filepath.Join(root, "second", ".gitlab", "CODEOWNERS")
+
+	writeTestFile(t, mapping, "# old new\n@alice @bob\n@carol\n")
+	writeTestFile(t, first, "# keep me\n*   @alice\n")
+	writeTestFile(t, second, "[Docs]\ndocs/ @carol\n")
+	writeTestFile(t, filepath.Join(root, "first", ".git", "CODEOWNERS"), "* @alice\n")
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"migrate", "-mapping", mapping, root}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Fatalf("got status %d, stderr %s", status, stderr.String())
+	}
+
+	if got := readTestFile(t, first); got != "# keep me\n* @bob\n" {
+		t.Errorf("unexpected content %q", got)
+	}
+
+	if got := readTestFile(t, second); got != "[Docs]\ndocs/\n" {
+		t.Errorf("unexpected content %q", got)
+	}
+
+	if got := readTestFile(t, filepath.Join(root, "first", ".git", "CODEOWNERS")); got != "* @alice\n" {
+		t.Errorf("file in .git directory was changed %q", got)
+	}
+
+	want := second + ":2: warning: rule 'docs/' in section 'Docs' has no owners anymore"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("stdout %q does not contain %q", stdout.String(), want)
+	}
+}
+
+func TestMigrate_dryRun(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mapping := filepath.Join(root, "mapping.txt")
+	path := filepath.Join(root, "CODEOWNERS")
+
+	writeTestFile(t, mapping, "@alice @bob\n")
+	writeTestFile(t, path, "* @alice\n")
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"migrate", "-mapping", mapping, "-dry-run", root}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Fatalf("got status %d, stderr %s", status, stderr.String())
+	}
+
+	if got := readTestFile(t, path); got != "* @alice\n" {
+		t.Errorf("file was changed during dry run %q", got)
+	}
+
+	if !strings.Contains(stdout.String(), "replaced 1, removed 0 owners") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
+
+func TestMigrate_invalidMapping(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mapping := filepath.Join(root, "mapping.txt")
+
+	writeTestFile(t, mapping, "@alice @bob @carol\n")
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"migrate", "-mapping", mapping, root}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	if status := run([]string{"migrate", root}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d, wanted %d", status, exitUsage)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+func runPolicy(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("policy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners policy -policy <file> [-root <directory>] [-format <format>] [file]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Checks a CODEOWNERS file against the rules of a JSON policy. The rules")
+		fmt.Fprintln(stderr, "for paths are checked for the files below the root directory if given,")
+		fmt.Fprintln(stderr, "otherwise the paths with wildcards are reported as unchecked.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	policyPath := flags.String("policy", "", "JSON file with the policy")
+	root := flags.String("root", "", "root directory of the repository, the paths in reports are relative to it (default: current directory)")
+	format := flags.String("format", formatText, "output format, 'text', 'codequality' for a Gitlab Code Quality report or 'sarif'")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if err := checkFormat(*format); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	if *policyPath == "" || flags.NArg() > 1 {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	policy, err := readPolicy(*policyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = defaultCodeOwnersFile()
+	}
+
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "no CODEOWNERS file found")
+
+		return exitFailure
+	}
+
+	file, err := readCodeOwnersFile(paths[0])
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	var fsys fs.FS
+	if *root != "" {
+		fsys = os.DirFS(*root)
+	}
+
+	diagnostics, err := policy.Evaluate(file, fsys)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	count, err := writeDiagnostics(stdout, *format, *root, policy.Checks(), []fileDiagnostics{{path: paths[0], diagnostics: diagnostics}})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	if count > 0 {
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+func readPolicy(path string) (gitlabcodeowners.Policy, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return gitlabcodeowners.Policy{}, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer reader.Close()
+
+	policy, err := gitlabcodeowners.ReadPolicy(reader)
+	if err != nil {
+		return gitlabcodeowners.Policy{}, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	return policy, nil
+}
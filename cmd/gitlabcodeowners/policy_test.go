@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicy_runPolicy(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	path := filepath.Join(root, "CODEOWNERS")
+	policy := filepath.Join(root, "policy.json")
+
+	writeTestFile(t, path, "* @alice\n/infra/ @org/infra\n")
+	writeTestFile(t, filepath.Join(root, "infra", "main.tf"), "")
+	writeTestFile(t, policy, `{"rules": [{"name": "infra", "kind": "min-approvals", "paths": ["/infra/**"], "approvals": 2}]}`)
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"policy", "-policy", policy, path}, nil, &stdout, &stderr); status != exitSuccess {
+		t.Errorf("got status %d without root directory, wanted %d", status, exitSuccess)
+	}
+
+	unchecked := path + ":0:0: info: /infra/** was not checked, because no files were given (policy:infra)\n"
+	if got := stdout.String(); got != unchecked {
+		t.Errorf("got %q, wanted %q", got, unchecked)
+	}
+
+	stdout.Reset()
+
+	if status := run([]string{"policy", "-policy", policy, "-root", root, path}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	want := path + ":0:0: error: /infra/main.tf requires a required section with at least 2 approvals, " +
+		"but at most 1 are required (policy:infra)\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	stdout.Reset()
+
+	args := []string{"policy", "-policy", policy, "-root", root, "-format", "sarif", path}
+	if status := run(args, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	if !strings.Contains(stdout.String(), `"ruleId": "policy:infra"`) {
+		t.Errorf("SARIF report %s does not contain the policy rule", stdout.String())
+	}
+
+	if status := run([]string{"policy", path}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d without policy, wanted %d", status, exitUsage)
+	}
+
+	writeTestFile(t, path, "* @alice\n\n[Infra][2] @org/infra\n/infra/ @bob @carol\n")
+
+	for _, args := range [][]string{{"policy", "-policy", policy, path}, {"policy", "-policy", policy, "-root", root, path}} {
+		stdout.Reset()
+
+		if status := run(args, nil, &stdout, &stderr); status != exitSuccess {
+			t.Errorf("got status %d and stdout %q for %v, wanted %d", status, stdout.String(), args, exitSuccess)
+		}
+	}
+}
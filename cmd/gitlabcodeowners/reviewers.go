@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chefe/gitlabcodeowners"
+)
+
+func runReviewers(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("reviewers", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gitlabcodeowners reviewers [flags] <file> <path...>")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Chooses reviewers for the required approvals of the changed paths")
+		fmt.Fprintln(stderr, "and prints one reviewer per line.")
+		fmt.Fprintln(stderr, "")
+		flags.PrintDefaults()
+	}
+
+	strategy := flags.String("strategy", string(gitlabcodeowners.ReviewerRoundRobin),
+		"'round-robin', 'least-recently-assigned' or 'random'")
+	statePath := flags.String("state", "", "JSON file which keeps the previous assignments")
+	author := flags.String("author", "", "author of the merge request, who is never chosen")
+	unavailable := flags.String("unavailable", "", "comma separated list of people who are never chosen")
+	seed := flags.Int64("seed", 0, "seed of the random strategy (default: the sequence of the state or the current time without state)")
+	membershipPath := flags.String("membership", "", "JSON snapshot of the users and groups to expand groups")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() < 2 { //nolint:gomnd // file and at least one path
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	file, err := readCodeOwnersFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	options := []gitlabcodeowners.ReviewerOption{}
+
+	switch {
+	case isFlagSet(flags, "seed"):
+		options = append(options, gitlabcodeowners.WithSeed(*seed))
+	case *statePath == "":
+		// without state every run would choose the same reviewers
+		options = append(options, gitlabcodeowners.WithSeed(time.Now().UnixNano()))
+	}
+
+	queryOptions := []gitlabcodeowners.QueryOption{gitlabcodeowners.WithPathNormalization()}
+
+	if *author != "" {
+		queryOptions = append(queryOptions, gitlabcodeowners.WithAuthorExcluded(*author))
+	}
+
+	if people := splitList(*unavailable); len(people) > 0 {
+		options = append(options, gitlabcodeowners.WithUnavailable(people...))
+	}
+
+	if *membershipPath != "" {
+		membership, err := readMembership(*membershipPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+
+			return exitFailure
+		}
+
+		queryOptions = append(queryOptions, gitlabcodeowners.WithOwnerResolver(membership))
+	}
+
+	state, err := readReviewerState(*statePath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	picker, err := gitlabcodeowners.NewReviewerPicker(gitlabcodeowners.ReviewerStrategy(*strategy), state, options...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	approvals := file.GetRequiredApprovalsForFiles(flags.Args()[1:], queryOptions...)
+	assignment := picker.Pick(approvals)
+
+	for _, reviewer := range assignment.Reviewers {
+		fmt.Fprintln(stdout, reviewer)
+	}
+
+	if err := writeReviewerState(*statePath, state); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	for _, section := range assignment.Unsatisfiable {
+		fmt.Fprintf(stderr, "section '%s' has not enough eligible reviewers\n", section)
+	}
+
+	if len(assignment.Unsatisfiable) > 0 {
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+// splitList splits the comma separated list and drops empty entries and the
+// whitespace around the entries.
+func splitList(list string) []string {
+	entries := []string{}
+
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// isFlagSet reports if the flag with the name was given on the command line.
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// readReviewerState reads the state at the path, a missing file is treated
+// as empty state.
+func readReviewerState(path string) (*gitlabcodeowners.ReviewerState, error) {
+	if path == "" {
+		return gitlabcodeowners.NewReviewerState(), nil
+	}
+
+	reader, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return gitlabcodeowners.NewReviewerState(), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer reader.Close()
+
+	state, err := gitlabcodeowners.ReadReviewerState(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	return state, nil
+}
+
+func writeReviewerState(path string, state *gitlabcodeowners.ReviewerState) error {
+	if path == "" {
+		return nil
+	}
+
+	buffer := bytes.Buffer{}
+
+	// writing into a bytes.Buffer never fails
+	_, _ = state.WriteTo(&buffer)
+
+	if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil { //nolint:gosec,gomnd // keep default permissions
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReviewers_runReviewers(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	path := filepath.Join(root, "CODEOWNERS")
+	state := filepath.Join(root, "state.json")
+
+	writeTestFile(t, path, "[Docs] @alice @bob @carol\ndocs/\n[Security][2] @erin\n/secrets/\n")
+
+	want := []string{"@bob\n", "@carol\n", "@bob\n"}
+
+	for i, want := range want {
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+		args := []string{"reviewers", "-state", state, "-author", "@alice", "-unavailable", "@dave,@erin", path, "docs/README.md"}
+		if status := run(args, nil, &stdout, &stderr); status != exitSuccess {
+			t.Fatalf("got status %d, stderr %s", status, stderr.String())
+		}
+
+		if got := stdout.String(); got != want {
+			t.Errorf("run %d: got %q, wanted %q", i, got, want)
+		}
+	}
+
+	if got := readTestFile(t, state); !strings.Contains(got, `"Docs docs/": 1`) {
+		t.Errorf("state %s does not contain the round-robin position", got)
+	}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	if status := run([]string{"reviewers", "-author", "@erin", path, "/secrets/key"}, nil, &stdout, &stderr); status != exitFailure {
+		t.Errorf("got status %d, wanted %d", status, exitFailure)
+	}
+
+	if got, want := stderr.String(), "section 'Security' has not enough eligible reviewers\n"; got != want {
+		t.Errorf("got stderr %q, wanted %q", got, want)
+	}
+
+	membership := filepath.Join(root, "membership.json")
+	writeTestFile(t, membership, `{"groups": {"@org/sec": ["@erin", "@frank"]}}`)
+	writeTestFile(t, path, "[Security] @org/sec\n/secrets/\n")
+	stdout.Reset()
+
+	args := []string{"reviewers", "-author", "@erin", "-membership", membership, path, "/secrets/key"}
+	if status := run(args, nil, &stdout, &stderr); status != exitSuccess || stdout.String() != "@frank\n" {
+		t.Errorf("got status %d and stdout %q, wanted the other member of the group", status, stdout.String())
+	}
+
+	writeTestFile(t, path, "[Docs] @alice @bob @carol\ndocs/\n")
+	stdout.Reset()
+
+	args = []string{"reviewers", "-unavailable", "@alice, @bob ,", path, "docs/README.md"}
+	if status := run(args, nil, &stdout, &stderr); status != exitSuccess || stdout.String() != "@carol\n" {
+		t.Errorf("got status %d and stdout %q, wanted the only available owner", status, stdout.String())
+	}
+
+	if status := run([]string{"reviewers", "-strategy", "first", path, "docs/"}, nil, &stdout, &stderr); status != exitUsage {
+		t.Errorf("got status %d for an unknown strategy, wanted %d", status, exitUsage)
+	}
+}
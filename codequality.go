@@ -0,0 +1,111 @@
+package gitlabcodeowners
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CodeQualityIssue is an entry of a Gitlab Code Quality report, which shows
+// the problems inline in the diff of a merge request.
+type CodeQualityIssue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+
+	// Fingerprint identifies the issue across pipelines, it does not depend
+	// on the line so moving a problem does not report it as a new issue.
+	Fingerprint string `json:"fingerprint"`
+
+	// Severity is one of `info`, `minor`, `major`, `critical` or `blocker`.
+	Severity string              `json:"severity"`
+	Location CodeQualityLocation `json:"location"`
+}
+
+// CodeQualityLocation is the location of a `CodeQualityIssue`.
+type CodeQualityLocation struct {
+	// Path is relative to the root of the repository.
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+// CodeQualityLines contains the line of a `CodeQualityLocation`.
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// CodeQualityIssues converts the diagnostics found in the file at the given
+// path to Gitlab Code Quality issues. The path is relative to the root of the
+// repository, a leading slash like in `/CODEOWNERS` is removed. Diagnostics
+// which are not related to a single line are reported at the first line.
+func CodeQualityIssues(path string, diagnostics []Diagnostic) []CodeQualityIssue {
+	path = reportPath(path)
+	issues := make([]CodeQualityIssue, 0, len(diagnostics))
+	seen := map[string]int{}
+
+	for _, diagnostic := range diagnostics {
+		key := path + "\x00" + diagnostic.Check + "\x00" + diagnostic.Message
+
+		// identical problems get distinct fingerprints by their occurrence
+		seen[key]++
+		if seen[key] > 1 {
+			key += "\x00" + strconv.Itoa(seen[key])
+		}
+
+		hash := sha256.Sum256([]byte(key))
+
+		issues = append(issues, CodeQualityIssue{
+			Description: diagnostic.Message,
+			CheckName:   diagnostic.Check,
+			Fingerprint: hex.EncodeToString(hash[:]),
+			Severity:    codeQualitySeverity(diagnostic.Severity),
+			Location: CodeQualityLocation{
+				Path:  path,
+				Lines: CodeQualityLines{Begin: max(diagnostic.Line, 1)},
+			},
+		})
+	}
+
+	return issues
+}
+
+// reportPath returns the path with forward slashes as used in reports, where
+// a leading slash which denotes the root of the repository is removed. The
+// path has to be relative to the root of the repository already.
+func reportPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+func codeQualitySeverity(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "major"
+	case SeverityWarning:
+		return "minor"
+	case SeverityInfo:
+		return "info"
+	}
+
+	return "info"
+}
+
+// WriteCodeQualityReport writes the issues as a Gitlab Code Quality report,
+// which can be uploaded with `artifacts:reports:codequality` in a job.
+func WriteCodeQualityReport(writer io.Writer, issues []CodeQualityIssue) error {
+	if issues == nil {
+		issues = []CodeQualityIssue{}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(issues); err != nil {
+		return fmt.Errorf("failed to write code quality report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package gitlabcodeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestCodeQuality_CodeQualityIssues(t *testing.T) {
+	t.Parallel()
+
+	diagnostics := []Diagnostic{
+		{Check: "parse-error", Severity: SeverityError, Line: 3, Column: 1, Message: "invalid rule"},
+		{Check: "policy:groups", Severity: SeverityWarning, Line: 0, Column: 0, Message: "no groups"},
+		{Check: "unsorted-rules", Severity: SeverityInfo, Line: 5, Column: 0, Message: "unsorted"},
+		{Check: "unsorted-rules", Severity: SeverityInfo, Line: 7, Column: 0, Message: "unsorted"},
+	}
+
+	issues := CodeQualityIssues("/docs/CODEOWNERS", diagnostics)
+
+	fingerprints := map[string]bool{}
+	for i := range issues {
+		fingerprints[issues[i].Fingerprint] = true
+		issues[i].Fingerprint = ""
+	}
+
+	if len(fingerprints) != len(diagnostics) {
+		t.Errorf("got %d distinct fingerprints, wanted %d", len(fingerprints), len(diagnostics))
+	}
+
+	location := func(line int) CodeQualityLocation {
+		return CodeQualityLocation{Path: "docs/CODEOWNERS", Lines: CodeQualityLines{Begin: line}}
+	}
+
+	testhelper.DeepEqual(t, issues, []CodeQualityIssue{
+		{Description: "invalid rule", CheckName: "parse-error", Fingerprint: "", Severity: "major", Location: location(3)},
+		{Description: "no groups", CheckName: "policy:groups", Fingerprint: "", Severity: "minor", Location: location(1)},
+		{Description: "unsorted", CheckName: "unsorted-rules", Fingerprint: "", Severity: "info", Location: location(5)},
+		{Description: "unsorted", CheckName: "unsorted-rules", Fingerprint: "", Severity: "info", Location: location(7)},
+	})
+
+	// the fingerprint does not depend on the line
+	moved := diagnostics[0]
+	moved.Line = 10
+
+	got := CodeQualityIssues("docs/CODEOWNERS", []Diagnostic{moved})[0].Fingerprint
+	if want := CodeQualityIssues("/docs/CODEOWNERS", diagnostics[:1])[0].Fingerprint; got != want {
+		t.Errorf("got fingerprint %s for the moved problem, wanted %s", got, want)
+	}
+}
+
+func TestCodeQuality_WriteCodeQualityReport(t *testing.T) {
+	t.Parallel()
+
+	buffer := bytes.Buffer{}
+
+	if err := WriteCodeQualityReport(&buffer, nil); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	testhelper.DeepEqual(t, buffer.String(), "[]\n")
+
+	buffer.Reset()
+
+	issues := CodeQualityIssues("CODEOWNERS", []Diagnostic{
+		{Check: "invalid-owner", Severity: SeverityError, Line: 2, Column: 4, Message: "invalid owner"},
+	})
+
+	if err := WriteCodeQualityReport(&buffer, issues); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	var report []map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	testhelper.DeepEqual(t, report, []map[string]any{{
+		"description": "invalid owner",
+		"check_name":  "invalid-owner",
+		"fingerprint": issues[0].Fingerprint,
+		"severity":    "major",
+		"location":    map[string]any{"path": "CODEOWNERS", "lines": map[string]any{"begin": 2.0}},
+	}})
+}
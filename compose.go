@@ -0,0 +1,155 @@
+package gitlabcodeowners
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultFragmentPattern matches the fragment files collected by
+// `ComposeFragments` if no other pattern is given.
+const DefaultFragmentPattern = "**/CODEOWNERS.fragment"
+
+// Fragment is a part of a `CODEOWNERS` file which only applies to the files
+// in its directory.
+type Fragment struct {
+	// Dir is the slash separated directory of the fragment relative to the
+	// root of the repository, an empty string or `.` stands for the root
+	// itself.
+	Dir string
+
+	// File contains the sections and rules of the fragment.
+	File File
+}
+
+// ComposeFragments collects all fragment files in the file system whose path
+// matches the pattern, for example `services/*/CODEOWNERS.fragment`, and
+// composes them with `Compose`. The fragments are written in the Gitlab
+// syntax and composed in the lexical order of their paths.
+func ComposeFragments(fsys fs.FS, pattern string) (File, error) {
+	fragments := []Fragment{}
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !fnmatch(pattern, filePath, false) {
+			return nil
+		}
+
+		reader, err := fsys.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open fragment '%s': %w", filePath, err)
+		}
+		defer reader.Close()
+
+		file, err := NewCodeOwnersFile(reader)
+		if err != nil {
+			return fmt.Errorf("failed to parse fragment '%s': %w", filePath, err)
+		}
+
+		fragments = append(fragments, Fragment{Dir: path.Dir(filePath), File: file})
+
+		return nil
+	})
+	if err != nil {
+		return emptyFile(), fmt.Errorf("failed to collect fragments: %w", err)
+	}
+
+	return Compose(fragments...), nil
+}
+
+// Compose merges the fragments into a single file. The patterns of each
+// fragment are rooted at its directory and sections with the same name are
+// merged case-insensitively like Gitlab does, so the first section header
+// defines the approval count of the merged section. Rules without owners
+// keep the default owners of the section header in their fragment.
+func Compose(fragments ...Fragment) File {
+	composed := emptyFile()
+	composed.sections = append(composed.sections, newSection("", 1, false, []string{}))
+
+	for _, fragment := range fragments {
+		for _, sec := range fragment.File.sections {
+			index := composed.findSection(sec.name)
+			if index < 0 {
+				target := newSection(sec.name, sec.approvals, sec.optional, append([]string{}, sec.owners...))
+				target.comment = sec.comment
+				target.src.leading = composedLeading(sec.src.leading)
+
+				composed.sections = append(composed.sections, target)
+				index = len(composed.sections) - 1
+			}
+
+			target := &composed.sections[index]
+			for _, r := range sec.rules {
+				target.rules = append(target.rules, composeRule(fragment.Dir, sec, *target, r))
+			}
+		}
+	}
+
+	// the rules without a section have to be written before the first
+	// section header, so the unnamed section always comes first
+	if len(composed.sections[0].rules) == 0 {
+		composed.sections = composed.sections[1:]
+	}
+
+	return composed
+}
+
+// composeRule returns a copy of the rule from the section of a fragment,
+// which can be added to the composed section.
+func composeRule(dir string, from, to section, r rule) rule {
+	owners := append([]string{}, r.owners...)
+	if len(owners) == 0 && !slices.Equal(from.owners, to.owners) {
+		owners = append(owners, from.owners...)
+	}
+
+	composed := newRule(newPattern(rootPattern(dir, r.pattern.value)), owners)
+	composed.selections = r.selections
+	composed.comment = r.comment
+	composed.src.leading = composedLeading(r.src.leading)
+
+	return composed
+}
+
+// rootPattern returns the pattern rooted at the given directory. Absolute
+// patterns start at the directory and relative patterns match anywhere
+// below the directory.
+func rootPattern(dir, pattern string) string {
+	dir = escapePattern(path.Clean("/" + dir))
+	if dir == "/" {
+		return pattern
+	}
+
+	if strings.HasPrefix(pattern, "/") {
+		return dir + pattern
+	}
+
+	return dir + "/**/" + pattern
+}
+
+// escapePattern escapes the whitespace and the special characters of a glob
+// pattern in the value, so the pattern only matches the value itself.
+func escapePattern(value string) string {
+	escaped := strings.Builder{}
+
+	for _, r := range value {
+		if unicode.IsSpace(r) || strings.ContainsRune("[]{}*?\\", r) {
+			escaped.WriteRune('\\')
+		}
+
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
+
+// composedLeading returns the comments directly above a line without alias
+// directives, because the aliases are already expanded.
+func composedLeading(leading []string) []string {
+	return withoutAliasDirectives(leading[commentBlockStart(leading):])
+}
@@ -0,0 +1,141 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCompose_rootPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dir     string
+		pattern string
+		want    string
+	}{
+		{name: "root directory", dir: ".", pattern: "*.md", want: "*.md"},
+		{name: "empty directory", dir: "", pattern: "/docs/", want: "/docs/"},
+		{name: "relative pattern", dir: "services/payments", pattern: "*.go", want: "/services/payments/**/*.go"},
+		{name: "catch all", dir: "services/payments", pattern: "*", want: "/services/payments/**/*"},
+		{name: "absolute pattern", dir: "services/payments/", pattern: "/api/", want: "/services/payments/api/"},
+		{name: "directory with whitespace", dir: "my service/", pattern: "docs/", want: "/my\\ service/**/docs/"},
+		{name: "directory with brackets", dir: "s[1]/", pattern: "/api/", want: "/s\\[1\\]/api/"},
+		{name: "directory with wildcards", dir: "{a,b}/*?", pattern: "*", want: "/\\{a,b\\}/\\*\\?/**/*"},
+		{name: "directory with backslash", dir: "services\\payments", pattern: "/api/", want: "/services\\\\payments/api/"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := rootPattern(tt.dir, tt.pattern); got != tt.want {
+				t.Errorf("got %s, wanted %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompose_ComposeFragments(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"CODEOWNERS.fragment": {Data: []byte("* @platform\n")},
+		"services/payments/CODEOWNERS.fragment": {Data: []byte(`# alias @payments = @alice @bob
+[Backend][2] @alice @bob
+# Public API
+# @contact #payments-chat
+/api/
+*.sql @dba # schema changes
+`)},
+		"services/search/CODEOWNERS.fragment": {Data: []byte(`* @search
+[backend] @search-backend
+/index/
+`)},
+		"services/search/CODEOWNERS": {Data: []byte("* @ignored\n")},
+	}
+
+	file, err := ComposeFragments(fsys, DefaultFragmentPattern)
+	if err != nil {
+		t.Fatalf("Failed to compose fragments: %v", err)
+	}
+
+	want := `* @platform
+/services/search/**/* @search
+
+[Backend][2] @alice @bob
+# Public API
+# @contact #payments-chat
+/services/payments/api/
+/services/payments/**/*.sql @dba # schema changes
+/services/search/index/ @search-backend
+`
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	// the composed file is valid and can be parsed again
+	parsed, err := NewCodeOwnersFile(strings.NewReader(file.String()), WithStrictParsing())
+	if err != nil {
+		t.Fatalf("Failed to parse composed file: %v", err)
+	}
+
+	approvals := parsed.GetRequiredApprovalsForFile("/services/search/index/main.go")
+	if got := approvals["Backend"].Owners; len(got) != 1 || got[0] != "@search-backend" {
+		t.Errorf("got owners %v, wanted [@search-backend]", got)
+	}
+
+	if got := approvals[""].Owners; len(got) != 1 || got[0] != "@search" {
+		t.Errorf("got owners %v, wanted [@search]", got)
+	}
+}
+
+func TestCompose_specialDirectories(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"my service/CODEOWNERS.fragment": {Data: []byte("docs/ @a\n")},
+		"s[1]/CODEOWNERS.fragment":       {Data: []byte("/api/ @b\n")},
+	}
+
+	file, err := ComposeFragments(fsys, DefaultFragmentPattern)
+	if err != nil {
+		t.Fatalf("Failed to compose fragments: %v", err)
+	}
+
+	want := "/my\\ service/**/docs/ @a\n/s\\[1\\]/api/ @b\n"
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	tests := map[string]string{
+		"/my service/docs/README.md": `/my\ service/**/docs/`,
+		"/s[1]/api/main.go":          `/s\[1\]/api/`,
+	}
+
+	for path, value := range tests {
+		if !newPattern(value).match(path, false) {
+			t.Errorf("pattern %s does not match %s", value, path)
+		}
+	}
+
+	if newPattern(`/s\[1\]/api/`).match("/s1/api/main.go", false) {
+		t.Errorf("pattern for the directory s[1] matches s1")
+	}
+}
+
+func TestCompose_noFragments(t *testing.T) {
+	t.Parallel()
+
+	file, err := ComposeFragments(fstest.MapFS{}, DefaultFragmentPattern)
+	if err != nil {
+		t.Fatalf("Failed to compose fragments: %v", err)
+	}
+
+	if got := file.String(); got != "" {
+		t.Errorf("got %q, wanted an empty file", got)
+	}
+}
@@ -0,0 +1,130 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errUnknownDialect = errors.New("unknown dialect")
+
+// Dialect selects the syntax flavor which is used to parse a `CODEOWNERS` file.
+type Dialect int
+
+const (
+	// DialectGitLab parses the file according to the Gitlab syntax,
+	// this is the default if no dialect is specified.
+	// See https://docs.gitlab.com/ee/user/project/codeowners/reference.html
+	DialectGitLab Dialect = iota
+
+	// DialectGitea parses the file according to the Gitea syntax, where
+	// each pattern is a regular expression which is optionally negated by
+	// a leading `!`. Gitea requests reviews from all matching rules, but
+	// like for the other dialects only the last matching rule is reported.
+	// See https://docs.gitea.com/usage/code-owners
+	DialectGitea
+
+	// DialectBitbucket parses the file according to the Bitbucket syntax,
+	// which supports reviewer groups (`@@team`) and reviewer selection
+	// directives like `Random(@@team, 2)` or `LeastBusy(@@team)`.
+	DialectBitbucket
+)
+
+// ParseOption configures how a `CODEOWNERS` file is parsed.
+type ParseOption func(*parseConfig)
+
+// GitLabMaxFileSize is the maximum size of a `CODEOWNERS` file in bytes,
+// Gitlab ignores larger files.
+// See https://docs.gitlab.com/ee/user/project/codeowners/reference.html
+const GitLabMaxFileSize = 3 * 1024 * 1024
+
+type parseConfig struct {
+	dialect     Dialect
+	strict      bool
+	maxFileSize int64
+}
+
+// WithDialect returns an option which parses the file with the given dialect.
+func WithDialect(dialect Dialect) ParseOption {
+	return func(config *parseConfig) {
+		config.dialect = dialect
+	}
+}
+
+// WithStrictParsing returns an option which fails parsing with a `ParseError`
+// on the first problem, instead of treating an unparsable section header as
+// rule like Gitlab does.
+func WithStrictParsing() ParseOption {
+	return func(config *parseConfig) {
+		config.strict = true
+	}
+}
+
+// WithMaxFileSize returns an option which fails parsing with a `ParseError`
+// caused by `ErrFileTooLarge` if the content is larger than the given number
+// of bytes. Use `GitLabMaxFileSize` to detect files which Gitlab ignores.
+func WithMaxFileSize(size int64) ParseOption {
+	return func(config *parseConfig) {
+		config.maxFileSize = size
+	}
+}
+
+func newParseConfig(options []ParseOption) parseConfig {
+	config := parseConfig{
+		dialect:     DialectGitLab,
+		strict:      false,
+		maxFileSize: 0,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectGitLab:
+		return "gitlab"
+	case DialectGitea:
+		return "gitea"
+	case DialectBitbucket:
+		return "bitbucket"
+	}
+
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// PossibleLocations returns a list of possible locations where a
+// `CODEOWNERS` file can be located according to the forge of the dialect.
+func (d Dialect) PossibleLocations() []string {
+	switch d {
+	case DialectGitLab:
+		return GetPossibleCodeOwnersLocations()
+	case DialectGitea:
+		return []string{"/CODEOWNERS", "/docs/CODEOWNERS", "/.gitea/CODEOWNERS"}
+	case DialectBitbucket:
+		return []string{"/.bitbucket/CODEOWNERS"}
+	}
+
+	return []string{}
+}
+
+func (d Dialect) parse(reader io.Reader, config parseConfig) (File, error) {
+	if config.maxFileSize > 0 {
+		reader = &sizeLimitReader{reader: reader, limit: config.maxFileSize, read: 0}
+	}
+
+	switch d {
+	case DialectGitLab:
+		return parseFile(reader, config)
+	case DialectGitea:
+		return parseGiteaFile(reader, config)
+	case DialectBitbucket:
+		return parseBitbucketFile(reader, config)
+	}
+
+	return File{}, fmt.Errorf("%w: %s", errUnknownDialect, d)
+}
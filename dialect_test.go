@@ -0,0 +1,218 @@
+package gitlabcodeowners
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestDialect_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{dialect: DialectGitLab, want: "gitlab"},
+		{dialect: DialectGitea, want: "gitea"},
+		{dialect: DialectBitbucket, want: "bitbucket"},
+		{dialect: Dialect(42), want: "Dialect(42)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.String(); got != tt.want {
+			t.Errorf("got %s, wanted %s", got, tt.want)
+		}
+	}
+}
+
+func TestDialect_PossibleLocations(t *testing.T) {
+	t.Parallel()
+
+	testhelper.DeepEqual(t, DialectGitLab.PossibleLocations(), GetPossibleCodeOwnersLocations())
+	testhelper.DeepEqual(t, DialectGitea.PossibleLocations(), []string{"/CODEOWNERS", "/docs/CODEOWNERS", "/.gitea/CODEOWNERS"})
+	testhelper.DeepEqual(t, DialectBitbucket.PossibleLocations(), []string{"/.bitbucket/CODEOWNERS"})
+}
+
+func TestDialect_unknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCodeOwnersFile(strings.NewReader("* @foo"), WithDialect(Dialect(42)))
+	if err == nil {
+		t.Errorf("expected an error for an unknown dialect")
+	}
+}
+
+func TestDialect_fixtures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fixture string
+		dialect Dialect
+		path    string
+		want    map[string]Approval
+	}{
+		{
+			name:    "gitea regular expression",
+			fixture: "testdata/gitea/CODEOWNERS",
+			dialect: DialectGitea,
+			path:    "/cmd/main.go",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "!frontend/src/.*\\.js",
+					Approvals: 1,
+					Owners:    []string{"@org1/team3", "@user5"},
+					Doc:       "You can use negative pattern",
+				},
+			},
+		},
+		{
+			name:    "gitea negated pattern does not match",
+			fixture: "testdata/gitea/CODEOWNERS",
+			dialect: DialectGitea,
+			path:    "/frontend/src/app.js",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "frontend/src/.*\\.js",
+					Approvals: 1,
+					Owners:    []string{"@org1/team1", "@org1/team2", "@user3"},
+					Doc:       "Comment too\nYou can assigning code owning for users or teams",
+				},
+			},
+		},
+		{
+			name:    "gitea alternatives",
+			fixture: "testdata/gitea/CODEOWNERS",
+			dialect: DialectGitea,
+			path:    "/docs/aws/setup.md",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "docs/(aws|google|azure)/[^/]*\\.(md|txt)",
+					Approvals: 1,
+					Owners:    []string{"@user8", "@org1/team4"},
+					Doc:       "You can use power of go regexp",
+				},
+			},
+		},
+		{
+			name:    "bitbucket default owner",
+			fixture: "testdata/bitbucket/CODEOWNERS",
+			dialect: DialectBitbucket,
+			path:    "/README.md",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "*",
+					Approvals: 1,
+					Owners:    []string{"@alice"},
+					Doc:       "Default reviewers for everything",
+				},
+			},
+		},
+		{
+			name:    "bitbucket reviewer group",
+			fixture: "testdata/bitbucket/CODEOWNERS",
+			dialect: DialectBitbucket,
+			path:    "/src/main.c",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "src/",
+					Approvals: 1,
+					Owners:    []string{"@@Backend"},
+					Doc:       "Reviewer groups use a double `@`",
+				},
+			},
+		},
+		{
+			name:    "bitbucket random selection",
+			fixture: "testdata/bitbucket/CODEOWNERS",
+			dialect: DialectBitbucket,
+			path:    "/docs/intro.md",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "docs/**",
+					Approvals: 1,
+					Owners:    []string{"@@Writers"},
+					Doc:       "Reviewer selection directives",
+					Selections: []ReviewerSelection{
+						{Strategy: SelectionRandom, Count: 2, Owners: []string{"@@Writers"}},
+					},
+				},
+			},
+		},
+		{
+			name:    "bitbucket least busy selection",
+			fixture: "testdata/bitbucket/CODEOWNERS",
+			dialect: DialectBitbucket,
+			path:    "/web/style.css",
+			want: map[string]Approval{
+				"": {
+					Pattern:   "*.css",
+					Approvals: 1,
+					Owners:    []string{"@bob", "@@Frontend"},
+					Selections: []ReviewerSelection{
+						{Strategy: SelectionLeastBusy, Count: 1, Owners: []string{"@@Frontend"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := os.Open(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer reader.Close()
+
+			file, err := NewCodeOwnersFile(reader, WithDialect(tt.dialect))
+			if err != nil {
+				t.Errorf("Failed to create code owners file: %v", err)
+			}
+
+			got := file.GetRequiredApprovalsForFile(tt.path)
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestDialect_tokenizeGiteaLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "empty line", line: "", want: []string{}},
+		{name: "comment", line: "# comment", want: []string{}},
+		{name: "inline comment", line: ".*\\\\.go @user # comment", want: []string{".*\\.go", "@user"}},
+		{name: "escaped whitespace", line: "with\\ space\t@user", want: []string{"with space", "@user"}},
+		{name: "escaped pound", line: "\\#file @user", want: []string{"#file", "@user"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testhelper.DeepEqual(t, tokenizeGiteaLine(tt.line), tt.want)
+		})
+	}
+}
+
+func TestDialect_tokenizeBitbucketLine(t *testing.T) {
+	t.Parallel()
+
+	got := tokenizeBitbucketLine("docs/  @alice Random(@@Writers, 2)\tLeastBusy( @@A )")
+	want := []string{"docs/", "@alice", "Random(@@Writers, 2)", "LeastBusy( @@A )"}
+	testhelper.DeepEqual(t, got, want)
+}
@@ -0,0 +1,88 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrReadFailed is the cause of a `ParseError` if the content could not be read.
+	ErrReadFailed = errors.New("error reading the file content")
+
+	// ErrFileTooLarge is the cause of a `ParseError` if the content is larger
+	// than the limit set with the `WithMaxFileSize` option.
+	ErrFileTooLarge = errors.New("file too large")
+
+	// ErrInvalidUTF8 is the cause of a `ParseError` if a line is not valid UTF-8.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+	// ErrNoMatchingBracketCount is the cause of a `ParseError` if a section
+	// header contains a different number of opening and closing brackets.
+	ErrNoMatchingBracketCount = errors.New("no matching bracket count")
+
+	// ErrTooMuchBracketsFound is the cause of a `ParseError` if a section
+	// header contains more than two pairs of brackets.
+	ErrTooMuchBracketsFound = errors.New("too much brackets found")
+
+	// ErrNoBracketsFound is the cause of a `ParseError` if a section
+	// header contains no brackets at all.
+	ErrNoBracketsFound = errors.New("no brackets found")
+
+	// ErrMalformedSectionHeader is the cause of a `ParseError` if the
+	// brackets of a section header are not in the expected order.
+	ErrMalformedSectionHeader = errors.New("malformed section header")
+
+	// ErrMissingSectionName is the cause of a `ParseError` if the name of
+	// a section header is empty.
+	ErrMissingSectionName = errors.New("missing section name")
+
+	// ErrEmptyRule is the cause of a `ParseError` if a rule has no pattern.
+	ErrEmptyRule = errors.New("empty rule")
+
+	// ErrInvalidAliasName is the cause of a `ParseError` if the name of an
+	// alias directive does not start with `@`.
+	ErrInvalidAliasName = errors.New("invalid alias name")
+
+	// ErrEmptyAlias is the cause of a `ParseError` if an alias directive
+	// does not contain any owners.
+	ErrEmptyAlias = errors.New("empty alias")
+
+	// ErrInvalidPattern is the cause of a `ParseError` if the pattern of a
+	// rule can not be compiled.
+	ErrInvalidPattern = errors.New("invalid pattern")
+
+	// ErrPatternTooComplex is the cause of a `ParseError` if the braces in
+	// the pattern of a rule expand to too many patterns.
+	ErrPatternTooComplex = errors.New("pattern too complex")
+)
+
+// ParseError describes a problem in a line of a `CODEOWNERS` file.
+// Use `errors.Is` with one of the `Err...` variables to check its cause.
+type ParseError struct {
+	// Line is the number of the line starting at 1.
+	Line int
+
+	// Column is the position of the problem in the line starting at 1,
+	// it is 0 if the problem does not belong to a specific position.
+	Column int
+
+	// Text is the line as it was read.
+	Text string
+
+	// Err is the cause of the problem.
+	Err error
+}
+
+// Error returns the position, the cause and the text of the problem.
+func (e *ParseError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d, column %d: %v: '%s'", e.Line, e.Column, e.Err, e.Text)
+}
+
+// Unwrap returns the cause of the problem.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
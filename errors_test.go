@@ -0,0 +1,127 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestErrors_ParseError(t *testing.T) {
+	t.Parallel()
+
+	err := error(&ParseError{Line: 3, Column: 7, Text: "[Docs]]", Err: ErrNoMatchingBracketCount})
+
+	if got, want := err.Error(), "line 3, column 7: no matching bracket count: '[Docs]]'"; got != want {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+
+	if !errors.Is(err, ErrNoMatchingBracketCount) {
+		t.Errorf("expected error to be %v", ErrNoMatchingBracketCount)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+		t.Errorf("expected error to be a parse error on line 3")
+	}
+
+	err = &ParseError{Line: 1, Column: 0, Text: "", Err: ErrReadFailed}
+	if got, want := err.Error(), "line 1: error reading the file content"; got != want {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+}
+
+func TestErrors_strictParsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		dialect Dialect
+		want    *ParseError
+	}{
+		{
+			name:    "missing closing bracket",
+			input:   "* @group\n\n[Section name\ndocs/ @docs_group",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 3, Column: 1, Text: "[Section name", Err: ErrNoMatchingBracketCount},
+		},
+		{
+			name:    "unmatched closing bracket",
+			input:   "  ^[Docs]] @docs",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 1, Column: 10, Text: "  ^[Docs]] @docs", Err: ErrNoMatchingBracketCount},
+		},
+		{
+			name:    "too much brackets",
+			input:   "[One][Two][Three]",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 1, Column: 11, Text: "[One][Two][Three]", Err: ErrTooMuchBracketsFound},
+		},
+		{
+			name:    "malformed header",
+			input:   "[ä]]b[",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 1, Column: 4, Text: "[ä]]b[", Err: ErrMalformedSectionHeader},
+		},
+		{
+			name:    "missing name",
+			input:   "\t[][2]",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 1, Column: 3, Text: "\t[][2]", Err: ErrMissingSectionName},
+		},
+		{
+			name:    "invalid regular expression",
+			input:   ".* @all\n  docs/(unclosed @docs",
+			dialect: DialectGitea,
+			want:    &ParseError{Line: 2, Column: 3, Text: "  docs/(unclosed @docs", Err: ErrInvalidPattern},
+		},
+		{
+			name:    "too many braces",
+			input:   "* @all\n{,}{,}{,}{,}{,}{,}{,}{,}{,}{,}{,} @docs",
+			dialect: DialectGitLab,
+			want:    &ParseError{Line: 2, Column: 1, Text: "{,}{,}{,}{,}{,}{,}{,}{,}{,}{,}{,} @docs", Err: ErrPatternTooComplex},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lenient, err := NewCodeOwnersFile(strings.NewReader(tt.input), WithDialect(tt.dialect))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			if got := lenient.ParseErrors(); len(got) != 1 || !errors.Is(got[0], tt.want.Err) {
+				t.Errorf("got parse errors %v, wanted %v", got, tt.want)
+			}
+
+			_, err = NewCodeOwnersFile(strings.NewReader(tt.input), WithDialect(tt.dialect), WithStrictParsing())
+
+			var got *ParseError
+			if !errors.As(err, &got) {
+				t.Fatalf("got error %v, wanted a parse error", err)
+			}
+
+			if !errors.Is(got, tt.want.Err) {
+				t.Errorf("got cause %v, wanted %v", got.Err, tt.want.Err)
+			}
+
+			got.Err = tt.want.Err
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestErrors_readError(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCodeOwnersFile(errorReader{})
+	if !errors.Is(err, ErrReadFailed) || !errors.Is(err, errSomethingFailed) {
+		t.Errorf("got error %v, wanted %v and %v", err, ErrReadFailed, errSomethingFailed)
+	}
+}
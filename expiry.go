@@ -0,0 +1,122 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// expiresAnnotation is the annotation which contains the date in the format
+// `YYYY-MM-DD` from which on a section or a rule no longer applies.
+const expiresAnnotation = "expires"
+
+// ErrInvalidExpiryDate is returned for an `@expires` annotation which does not
+// contain a date in the format `YYYY-MM-DD`.
+var ErrInvalidExpiryDate = errors.New("invalid expiry date")
+
+// ExpiredEntry describes a section or a rule with an `@expires` annotation
+// whose date has passed or could not be parsed.
+type ExpiredEntry struct {
+	// Section is the name of the section which contains the entry.
+	Section string
+
+	// Pattern is the pattern of the expired rule, it is empty if the
+	// section itself expired.
+	Pattern string
+
+	// Line is the line of the section header or the rule, it is 0 if the
+	// entry was not read from a file.
+	Line int
+
+	// Expires is the date from which on the entry no longer applies.
+	Expires time.Time
+
+	// Err is set if the date of the annotation could not be parsed.
+	Err error
+}
+
+// WithExpiredOwnersIgnored returns an option which ignores sections and rules
+// whose `@expires` date is not after `now`, as if they were not in the file.
+func WithExpiredOwnersIgnored(now time.Time) QueryOption {
+	return func(config *queryConfig) {
+		config.now = now
+	}
+}
+
+// WithExpiredOwnersReported returns an option which ignores expired sections
+// and rules like `WithExpiredOwnersIgnored`, but reports the approvals they
+// would have required in `QueryResult.Expired`.
+func WithExpiredOwnersReported(now time.Time) QueryOption {
+	return func(config *queryConfig) {
+		config.now = now
+		config.reportExpired = true
+	}
+}
+
+// ExpiredEntries returns all sections and rules which expired at the given
+// time, including the ones with an invalid `@expires` annotation.
+func (f File) ExpiredEntries(now time.Time) []ExpiredEntry {
+	entries := []ExpiredEntry{}
+
+	for _, sec := range f.sections {
+		if entry, ok := expiredEntry(sec.src, now); ok {
+			entry.Section = sec.name
+			entries = append(entries, entry)
+		}
+
+		for _, r := range sec.rules {
+			if entry, ok := expiredEntry(r.src, now); ok {
+				entry.Section = sec.name
+				entry.Pattern = r.pattern.value
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries
+}
+
+func expiredEntry(src source, now time.Time) (ExpiredEntry, bool) {
+	expires, ok, err := src.expiryDate()
+	if !ok || (err == nil && now.Before(expires)) {
+		return ExpiredEntry{}, false //nolint:exhaustruct // not used if nothing expired
+	}
+
+	return ExpiredEntry{
+		Section: "",
+		Pattern: "",
+		Line:    src.line,
+		Expires: expires,
+		Err:     err,
+	}, true
+}
+
+// expiryDate returns the date of the `@expires` annotation above the line,
+// the boolean is false if there is no such annotation.
+func (s source) expiryDate() (time.Time, bool, error) {
+	_, annotations := s.docComment()
+
+	value, ok := annotations[expiresAnnotation]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	expires, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("%w: %w", ErrInvalidExpiryDate, err)
+	}
+
+	return expires, true, nil
+}
+
+// isExpired reports if the section or rule read from the given source is
+// expired, entries with an invalid date never expire.
+func (c queryConfig) isExpired(src source) bool {
+	if c.now.IsZero() {
+		return false
+	}
+
+	expires, ok, err := src.expiryDate()
+
+	return ok && err == nil && !c.now.Before(expires)
+}
@@ -0,0 +1,142 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const expiryTestFile = `* @general
+
+# @expires 2027-01-01
+[Incident] @incident-team
+*.go
+
+[Backend] @backend
+/api/
+# temporary owner during the migration
+# @expires 2026-06-30
+/api/legacy/ @migration-team
+# @expires someday
+/cmd/ @cli-team
+`
+
+func TestExpiry_ExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(expiryTestFile))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{
+			name: "nothing expired",
+			now:  time.Date(2026, 6, 29, 23, 59, 0, 0, time.UTC),
+			want: []string{"Backend /cmd/ 13"},
+		},
+		{
+			name: "rule expired",
+			now:  time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+			want: []string{"Backend /api/legacy/ 11", "Backend /cmd/ 13"},
+		},
+		{
+			name: "section and rule expired",
+			now:  time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: []string{"Incident  4", "Backend /api/legacy/ 11", "Backend /cmd/ 13"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := []string{}
+
+			for _, entry := range file.ExpiredEntries(tt.now) {
+				got = append(got, fmt.Sprintf("%s %s %d", entry.Section, entry.Pattern, entry.Line))
+
+				if (entry.Pattern == "/cmd/") != errors.Is(entry.Err, ErrInvalidExpiryDate) {
+					t.Errorf("unexpected error %v for entry %s", entry.Err, entry.Pattern)
+				}
+			}
+
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestExpiry_QueryFile(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(expiryTestFile))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	now := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	general := Approval{Pattern: "*", Approvals: 1, Owners: []string{"@general"}}
+	backend := Approval{Pattern: "/api/", Approvals: 1, Owners: []string{"@backend"}}
+	legacy := Approval{
+		Pattern:     "/api/legacy/",
+		Approvals:   1,
+		Owners:      []string{"@migration-team"},
+		Doc:         "temporary owner during the migration",
+		Annotations: map[string]string{"expires": "2026-06-30"},
+	}
+	incident := Approval{
+		Pattern:     "*.go",
+		Approvals:   1,
+		Owners:      []string{"@incident-team"},
+		Annotations: map[string]string{"expires": "2027-01-01"},
+	}
+
+	tests := []struct {
+		name        string
+		options     []QueryOption
+		wantActive  map[string]Approval
+		wantExpired map[string]Approval
+	}{
+		{
+			name:        "expiry is ignored without option",
+			options:     nil,
+			wantActive:  map[string]Approval{"": general, "Incident": incident, "Backend": legacy},
+			wantExpired: nil,
+		},
+		{
+			name:        "expired owners are absent",
+			options:     []QueryOption{WithExpiredOwnersIgnored(now)},
+			wantActive:  map[string]Approval{"": general, "Backend": backend},
+			wantExpired: nil,
+		},
+		{
+			name:        "expired owners are reported",
+			options:     []QueryOption{WithExpiredOwnersReported(now)},
+			wantActive:  map[string]Approval{"": general, "Backend": backend},
+			wantExpired: map[string]Approval{"Incident": incident, "Backend": legacy},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := file.QueryFile("/api/legacy/main.go", tt.options...)
+
+			testhelper.DeepEqual(t, got.Approvals, tt.wantActive)
+			testhelper.DeepEqual(t, got.Expired, tt.wantExpired)
+		})
+	}
+}
@@ -11,13 +11,41 @@ import (
 // File is a representation of a parsed `CODEOWNERS` file.
 type File struct {
 	sections []section
+
+	// trailing contains the comments and empty lines at the end of the file.
+	trailing []string
+
+	// parseErrors contains the problems found while parsing the file.
+	parseErrors []*ParseError
+
+	// byteOrderMark is set if the file started with an UTF-8 byte order mark.
+	byteOrderMark bool
+
+	// crlf is set if the first line of the file ended with `\r\n`.
+	crlf bool
 }
 
 // Approval describes an approval required by a rule in the `CODEOWNERS` file.
 type Approval struct {
-	Pattern   string
-	Approvals int
-	Owners    []string
+	Pattern    string
+	Approvals  int
+	Owners     []string
+	Selections []ReviewerSelection
+
+	// Comment is the text of the inline comment on the matching rule.
+	Comment string
+
+	// Doc is the text of the comment block directly above the matching rule.
+	Doc string
+
+	// SectionDoc is the text of the comment block directly above the
+	// section header.
+	SectionDoc string
+
+	// Annotations contains the annotations like `@contact` from the comment
+	// blocks above the section header and the matching rule, where the
+	// annotations of the rule take precedence.
+	Annotations map[string]string
 }
 
 // GetPossibleCodeOwnersLocations returns a list of possible locations
@@ -27,58 +55,112 @@ func GetPossibleCodeOwnersLocations() []string {
 }
 
 // NewCodeOwnersFile tries to parse the given description and returns a `File`
-// instance if parsing succeeded otherwise it return an error.
-func NewCodeOwnersFile(reader io.Reader) (File, error) {
-	sections, err := parseFile(reader)
+// instance if parsing succeeded otherwise it return an error. The file is
+// parsed with the Gitlab syntax unless another dialect is selected with
+// the `WithDialect` option.
+func NewCodeOwnersFile(reader io.Reader, options ...ParseOption) (File, error) {
+	config := newParseConfig(options)
+
+	file, err := config.dialect.parse(reader, config)
 	if err != nil {
 		return File{}, err
 	}
 
-	return File{sections: sections}, nil
+	return file, nil
+}
+
+// ParseErrors returns the problems which were found while parsing the file,
+// but which did not prevent parsing it. For example Gitlab treats a section
+// header which can not be parsed as a rule. Use the `WithStrictParsing`
+// option to fail on the first problem instead.
+func (f File) ParseErrors() []*ParseError {
+	return append([]*ParseError{}, f.parseErrors...)
 }
 
 // GetRequiredApprovalsForFile returns a map of all approvals which
 // apply to the file given by it's path. All path need to start with
-// a `/` which represents the root folder of the repository.
-func (f File) GetRequiredApprovalsForFile(path string) map[string]Approval {
+// a `/` which represents the root folder of the repository, unless
+// the `WithPathNormalization` option is used.
+func (f File) GetRequiredApprovalsForFile(path string, options ...QueryOption) map[string]Approval {
+	return f.QueryFile(path, options...).Approvals
+}
+
+func (f File) requiredApprovals(path string, config queryConfig) (map[string]Approval, map[string]Approval) {
 	requiredApprovals := map[string]Approval{}
 
-	for _, sec := range f.sections {
-		found := false
-		rule := rule{} //nolint:exhaustruct // used as placeholder if no rule is found
+	var expiredApprovals map[string]Approval
+	if config.reportExpired {
+		expiredApprovals = map[string]Approval{}
+	}
 
-		for _, r := range sec.rules {
-			if isValidRule(r, sec.owners) && r.pattern.match(path) {
-				rule = r
-				found = true
-			}
+	for _, sec := range f.sections {
+		if !config.includesSection(sec.name) {
+			continue
 		}
 
-		if found {
-			owners := sec.owners
-			if len(rule.owners) > 0 {
-				owners = rule.owners
+		sectionExpired := config.isExpired(sec.src)
+
+		var active, expired *rule
+
+		for i, r := range sec.rules {
+			if !isValidRule(r, sec.owners) || !r.pattern.match(path, config.caseInsensitive) {
+				continue
 			}
 
-			requiredApprovals[sec.name] = Approval{
-				Pattern:   rule.pattern.value,
-				Approvals: sec.approvals,
-				Owners:    owners,
+			// an expired rule is treated as absent, but it is reported
+			// if it would have overridden the previous matching rule
+			if sectionExpired || config.isExpired(r.src) {
+				expired = &sec.rules[i]
+
+				continue
 			}
+
+			active = &sec.rules[i]
+			expired = nil
+		}
+
+		if active != nil {
+			requiredApprovals[sec.name] = newApproval(sec, *active)
+		}
+
+		if expired != nil && expiredApprovals != nil {
+			expiredApprovals[sec.name] = newApproval(sec, *expired)
 		}
 	}
 
-	return requiredApprovals
+	return requiredApprovals, expiredApprovals
+}
+
+func newApproval(sec section, rule rule) Approval {
+	owners := sec.owners
+	if len(rule.owners) > 0 {
+		owners = rule.owners
+	}
+
+	sectionDoc, sectionAnnotations := sec.src.docComment()
+	doc, ruleAnnotations := rule.src.docComment()
+
+	return Approval{
+		Pattern:     rule.pattern.value,
+		Approvals:   sec.approvals,
+		Owners:      owners,
+		Selections:  rule.selections,
+		Comment:     rule.comment,
+		Doc:         doc,
+		SectionDoc:  sectionDoc,
+		Annotations: mergeAnnotations(sectionAnnotations, ruleAnnotations),
+	}
 }
 
 // GetRequiredApprovalsForFiles returns a map of all approvals which
 // apply to the files given by their path. All paths need to start with
-// a `/` which represents the root folder of the repository.
-func (f File) GetRequiredApprovalsForFiles(paths []string) map[string][]Approval {
+// a `/` which represents the root folder of the repository, unless
+// the `WithPathNormalization` option is used.
+func (f File) GetRequiredApprovalsForFiles(paths []string, options ...QueryOption) map[string][]Approval {
 	requiredApprovals := map[string][]Approval{}
 
 	for _, path := range paths {
-		for section, approval := range f.GetRequiredApprovalsForFile(path) {
+		for section, approval := range f.GetRequiredApprovalsForFile(path, options...) {
 			existingApprovals := requiredApprovals[section]
 			requiredApprovals[section] = append(existingApprovals, approval)
 		}
@@ -91,6 +173,27 @@ func (f File) GetRequiredApprovalsForFiles(paths []string) map[string][]Approval
 	return requiredApprovals
 }
 
+// GetUnsatisfiableApprovalsForFiles returns the approvals for the files given
+// by their path, which can not be approved because too many of their owners
+// are excluded with the `WithAuthorExcluded` or `WithCommittersExcluded`
+// options.
+// The owners of the returned approvals are the owners before the exclusion.
+func (f File) GetUnsatisfiableApprovalsForFiles(paths []string, options ...QueryOption) map[string][]Approval {
+	unsatisfiable := map[string][]Approval{}
+
+	for _, path := range paths {
+		for section, approval := range f.QueryFile(path, options...).Unsatisfiable {
+			unsatisfiable[section] = append(unsatisfiable[section], approval)
+		}
+	}
+
+	for section, approvals := range unsatisfiable {
+		unsatisfiable[section] = removeDuplicatedApprovals(approvals)
+	}
+
+	return unsatisfiable
+}
+
 func isValidRule(rule rule, defaultOwners []string) bool {
 	return (len(rule.owners) + len(defaultOwners)) > 0
 }
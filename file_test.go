@@ -136,6 +136,7 @@ config/db/database-setup.md @docs-team
 					Approvals: 1,
 					Pattern:   "*",
 					Owners:    []string{"@general-approvers"},
+					Doc:       "Required for all files",
 				},
 				"Documentation": {
 					Pattern:   "*.txt",
@@ -221,6 +222,7 @@ config/db/database-setup.md @docs-team
 						Approvals: 1,
 						Pattern:   "*",
 						Owners:    []string{"@general-approvers"},
+						Doc:       "Required for all files",
 					},
 				},
 				"Documentation": {
@@ -0,0 +1,387 @@
+package gitlabcodeowners
+
+import (
+	"unicode"
+)
+
+// This file contains a port of Ruby's `File.fnmatch?` as implemented in
+// `dir.c`, which Gitlab uses to match the normalized pattern of a rule
+// against the path of a file. Only the flags used by Gitlab are supported:
+// `FNM_PATHNAME`, `FNM_DOTMATCH` and `FNM_EXTGLOB`, which means:
+//
+//   - `*`, `?` and bracket expressions never match a `/`
+//   - `**/` matches zero or more directories
+//   - wildcards match names starting with a `.`
+//   - braces like `{a,b}` are expanded before matching
+//   - a `\` escapes the following character
+//
+// Additionally `FNM_CASEFOLD` can be enabled to match case-insensitively.
+
+// fnmatch reports whether the path matches the pattern like
+// `File.fnmatch?(pattern, path, FNM_PATHNAME | FNM_DOTMATCH | FNM_EXTGLOB)`.
+// If `caseFold` is set, `FNM_CASEFOLD` is added to the flags.
+func fnmatch(pattern, path string, caseFold bool) bool {
+	globs, _ := expandGlobs(pattern)
+
+	return fnmatchGlobs(globs, path, caseFold)
+}
+
+// fnmatchGlobs is like `fnmatch` for a pattern whose braces were already
+// expanded by `expandGlobs`.
+func fnmatchGlobs(globs [][]rune, path string, caseFold bool) bool {
+	runes := []rune(path)
+
+	for _, glob := range globs {
+		if fnmatchPathname(glob, runes, caseFold) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandGlobs returns the patterns of `expandBraces` ready to be matched.
+func expandGlobs(pattern string) ([][]rune, bool) {
+	expanded, complete := expandBraces(pattern)
+	globs := make([][]rune, 0, len(expanded))
+
+	for _, glob := range expanded {
+		globs = append(globs, []rune(glob))
+	}
+
+	return globs, complete
+}
+
+const (
+	// maxBraceExpansions limits the number of patterns a single pattern
+	// expands to, because a few braces in a hostile pattern can expand
+	// to millions.
+	maxBraceExpansions = 1024
+
+	// maxBraceExpansionWork limits the number of runes which are copied
+	// while expanding the braces of a single pattern, because many braces
+	// in a long pattern need a lot of work even for a few expansions.
+	maxBraceExpansionWork = 1 << 20
+)
+
+// expandBraces returns all patterns which result from expanding the braces
+// in the given pattern. Like in Ruby a pattern with an opening brace but
+// without a matching closing brace expands to nothing and matches nothing.
+// The expansion stops after `maxBraceExpansions` patterns or as soon as
+// the work exceeds `maxBraceExpansionWork`, in which case the boolean is
+// false and only the patterns expanded so far are returned.
+func expandBraces(pattern string) ([]string, bool) {
+	expander := braceExpander{expansions: maxBraceExpansions, work: maxBraceExpansionWork, truncated: false}
+	expanded := expander.expand([]rune(pattern))
+
+	return expanded, !expander.truncated
+}
+
+// braceExpander keeps track of the remaining budget of an expansion.
+type braceExpander struct {
+	expansions int
+	work       int
+	truncated  bool
+}
+
+func (e *braceExpander) expand(runes []rune) []string {
+	e.work -= len(runes) + 1
+	if e.work < 0 {
+		e.truncated = true
+
+		return nil
+	}
+
+	lbrace, rbrace := findBraces(runes)
+
+	switch {
+	case lbrace < 0:
+		if e.expansions == 0 {
+			e.truncated = true
+
+			return nil
+		}
+
+		e.expansions--
+
+		return []string{string(runes)}
+	case rbrace < 0:
+		return []string{}
+	}
+
+	prefix, suffix := runes[:lbrace], runes[rbrace+1:]
+	expanded := []string{}
+
+	for i := lbrace; i < rbrace && !e.truncated; {
+		i++
+		start := i
+		nest := 0
+
+		for i < rbrace && (runes[i] != ',' || nest != 0) {
+			switch runes[i] {
+			case '{':
+				nest++
+			case '}':
+				nest--
+			case '\\':
+				// skip the escaped character, but never the closing brace
+				if i+1 < rbrace {
+					i++
+				}
+			}
+
+			i++
+		}
+
+		alternative := make([]rune, 0, len(prefix)+i-start+len(suffix))
+		alternative = append(append(append(alternative, prefix...), runes[start:i]...), suffix...)
+		expanded = append(expanded, e.expand(alternative)...)
+	}
+
+	return expanded
+}
+
+// findBraces returns the positions of the first opening brace and of its
+// closing brace, or -1 if there is no such brace.
+func findBraces(runes []rune) (int, int) {
+	lbrace, nest := -1, 0
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '{' {
+			if nest == 0 {
+				lbrace = i
+			}
+
+			nest++
+		}
+
+		if runes[i] == '}' && lbrace >= 0 {
+			nest--
+
+			if nest == 0 {
+				return lbrace, i
+			}
+		}
+
+		if runes[i] == '\\' {
+			i++
+		}
+	}
+
+	return lbrace, -1
+}
+
+// fnmatchPathname matches the path segment by segment and handles `**/`.
+func fnmatchPathname(pattern, path []rune, caseFold bool) bool {
+	p, s := 0, 0
+	ptmp, stmp := -1, -1
+
+	for {
+		if isDoubleStarSlash(pattern, p) {
+			for isDoubleStarSlash(pattern, p) {
+				p += 3
+			}
+
+			ptmp, stmp = p, s
+		}
+
+		var ok bool
+
+		p, s, ok = fnmatchSegment(pattern, p, path, s, caseFold)
+		if ok {
+			for s < len(path) && path[s] != '/' {
+				s++
+			}
+
+			if p < len(pattern) && s < len(path) {
+				p++
+				s++
+
+				continue
+			}
+
+			if p >= len(pattern) && s >= len(path) {
+				return true
+			}
+		}
+
+		// let `**/` match one more directory and try again
+		if ptmp >= 0 {
+			for stmp < len(path) && path[stmp] != '/' {
+				stmp++
+			}
+
+			if stmp < len(path) {
+				stmp++
+				p, s = ptmp, stmp
+
+				continue
+			}
+		}
+
+		return false
+	}
+}
+
+// fnmatchSegment matches a single segment of the path, which ends at the
+// next `/`. It returns the positions where matching stopped and whether
+// the segment matched.
+func fnmatchSegment(pattern []rune, p int, path []rune, s int, caseFold bool) (int, int, bool) { //nolint:cyclop,lll // keep close to the original
+	ptmp, stmp := -1, -1
+
+	for {
+		matched := false
+
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			for p < len(pattern) && pattern[p] == '*' {
+				p++
+			}
+
+			if isSegmentEnd(pattern, unescape(pattern, p)) {
+				return unescape(pattern, p), s, true
+			}
+
+			if isSegmentEnd(path, s) {
+				return p, s, false
+			}
+
+			ptmp, stmp = p, s
+
+			continue
+
+		case p < len(pattern) && pattern[p] == '?':
+			if isSegmentEnd(path, s) {
+				return p, s, false
+			}
+
+			p++
+			s++
+
+			continue
+
+		case p < len(pattern) && pattern[p] == '[':
+			if isSegmentEnd(path, s) {
+				return p, s, false
+			}
+
+			if next, ok := matchBracket(pattern, p+1, path[s], caseFold); ok {
+				p = next
+				s++
+				matched = true
+			}
+
+		default:
+			p = unescape(pattern, p)
+
+			if isSegmentEnd(path, s) {
+				return p, s, isSegmentEnd(pattern, p)
+			}
+
+			if !isSegmentEnd(pattern, p) && equalRune(pattern[p], path[s], caseFold) {
+				p++
+				s++
+				matched = true
+			}
+		}
+
+		if matched {
+			continue
+		}
+
+		// let the last `*` match one more character and try again
+		if ptmp >= 0 {
+			stmp++
+			p, s = ptmp, stmp
+
+			continue
+		}
+
+		return p, s, false
+	}
+}
+
+// matchBracket matches the character against the bracket expression
+// starting at `p`, which is the position after the opening `[`. It returns
+// the position after the closing `]` if the character matched.
+func matchBracket(pattern []rune, p int, char rune, caseFold bool) (int, bool) {
+	if p >= len(pattern) {
+		return 0, false
+	}
+
+	negated := pattern[p] == '!' || pattern[p] == '^'
+	if negated {
+		p++
+	}
+
+	matched := false
+
+	for p >= len(pattern) || pattern[p] != ']' {
+		first := unescape(pattern, p)
+		if first >= len(pattern) {
+			return 0, false
+		}
+
+		p = first + 1
+		if p >= len(pattern) {
+			return 0, false
+		}
+
+		if pattern[p] == '-' && (p+1 >= len(pattern) || pattern[p+1] != ']') {
+			last := unescape(pattern, p+1)
+			if last >= len(pattern) {
+				return 0, false
+			}
+
+			p = last + 1
+
+			if isInRange(char, pattern[first], pattern[last], caseFold) {
+				matched = true
+			}
+
+			continue
+		}
+
+		if equalRune(char, pattern[first], caseFold) {
+			matched = true
+		}
+	}
+
+	if matched == negated {
+		return 0, false
+	}
+
+	return p + 1, true
+}
+
+func isInRange(char, first, last rune, caseFold bool) bool {
+	if equalRune(char, first, caseFold) || equalRune(char, last, caseFold) {
+		return true
+	}
+
+	if caseFold {
+		char, first, last = unicode.ToUpper(char), unicode.ToUpper(first), unicode.ToUpper(last)
+	}
+
+	return char >= first && char <= last
+}
+
+func equalRune(a, b rune, caseFold bool) bool {
+	return a == b || (caseFold && unicode.ToUpper(a) == unicode.ToUpper(b))
+}
+
+func isDoubleStarSlash(pattern []rune, p int) bool {
+	return p+2 < len(pattern) && pattern[p] == '*' && pattern[p+1] == '*' && pattern[p+2] == '/'
+}
+
+func isSegmentEnd(runes []rune, i int) bool {
+	return i >= len(runes) || runes[i] == '/'
+}
+
+func unescape(pattern []rune, p int) int {
+	if p < len(pattern) && pattern[p] == '\\' {
+		return p + 1
+	}
+
+	return p
+}
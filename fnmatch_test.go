@@ -0,0 +1,212 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestFnmatch_fnmatch(t *testing.T) {
+	t.Parallel()
+
+	// examples from the documentation of Ruby's `File.fnmatch`, the expected
+	// values are the ones for `FNM_PATHNAME | FNM_DOTMATCH | FNM_EXTGLOB`
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "cat", path: "cat", want: true},
+		{pattern: "cat", path: "category", want: false},
+		{pattern: "c{at,ub}s", path: "cats", want: true},
+		{pattern: "c{at,ub}s", path: "cubs", want: true},
+		{pattern: "c{at,ub}s", path: "cuts", want: false},
+		{pattern: "c?t", path: "cat", want: true},
+		{pattern: "c??t", path: "cat", want: false},
+		{pattern: "c*", path: "cats", want: true},
+		{pattern: "c*t", path: "c/a/b/t", want: false},
+		{pattern: "ca[a-z]", path: "cat", want: true},
+		{pattern: "ca[^t]", path: "cat", want: false},
+		{pattern: "ca[!t]", path: "cab", want: true},
+		{pattern: "cat", path: "CAT", want: false},
+		{pattern: "?", path: "/", want: false},
+		{pattern: "*", path: "/", want: false},
+		{pattern: "[/]", path: "/", want: false},
+		{pattern: "\\?", path: "?", want: true},
+		{pattern: "\\a", path: "a", want: true},
+		{pattern: "\\a", path: "\\a", want: false},
+		{pattern: "[\\?]", path: "?", want: true},
+		{pattern: "*", path: ".profile", want: true},
+		{pattern: ".*", path: ".profile", want: true},
+		{pattern: "**/*.rb", path: "main.rb", want: true},
+		{pattern: "**/*.rb", path: "./main.rb", want: true},
+		{pattern: "**/*.rb", path: "lib/song.rb", want: true},
+		{pattern: "**.rb", path: "main.rb", want: true},
+		{pattern: "**.rb", path: "./main.rb", want: false},
+		{pattern: "**.rb", path: "lib/song.rb", want: false},
+		{pattern: "*", path: "dave/.profile", want: false},
+		{pattern: "*/*", path: "dave/.profile", want: true},
+		{pattern: "**/foo", path: "a/b/c/foo", want: true},
+		{pattern: "**/foo", path: "/a/b/c/foo", want: true},
+		{pattern: "**/foo", path: "c:/a/b/c/foo", want: true},
+		{pattern: "**/foo", path: "a/.b/c/foo", want: true},
+		{pattern: "**/foo", path: "a/b/c/foobar", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := fnmatch(tt.pattern, tt.path, false); got != tt.want {
+			t.Errorf("pattern %s, path %s -> got %t, wanted %t", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFnmatch_brackets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "[abc].md", path: "b.md", want: true},
+		{pattern: "[abc].md", path: "d.md", want: false},
+		{pattern: "[a-c].md", path: "c.md", want: true},
+		{pattern: "[c-a].md", path: "a.md", want: true},
+		{pattern: "[c-a].md", path: "b.md", want: false},
+		{pattern: "[a-].md", path: "-.md", want: true},
+		{pattern: "[]].md", path: "].md", want: false},
+		{pattern: "[^a-c].md", path: "d.md", want: true},
+		{pattern: "[ä-ü].md", path: "ö.md", want: true},
+		{pattern: "[abc.md", path: "a.md", want: false},
+		{pattern: "[abc.md", path: "[abc.md", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := fnmatch(tt.pattern, tt.path, false); got != tt.want {
+			t.Errorf("pattern %s, path %s -> got %t, wanted %t", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFnmatch_expandBraces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "no braces", pattern: "/docs/*.md", want: []string{"/docs/*.md"}},
+		{name: "simple", pattern: "*.{md,txt}", want: []string{"*.md", "*.txt"}},
+		{name: "multiple", pattern: "{a,b}/{c,d}", want: []string{"a/c", "a/d", "b/c", "b/d"}},
+		{name: "nested", pattern: "{a,b{c,d}}", want: []string{"a", "bc", "bd"}},
+		{name: "empty alternative", pattern: "a{,b}", want: []string{"a", "ab"}},
+		{name: "escaped brace", pattern: "\\{a,b}", want: []string{"\\{a,b}"}},
+		{name: "escaped comma", pattern: "{a\\,b,c}", want: []string{"a\\,b", "c"}},
+		{name: "unbalanced opening brace", pattern: "{a,b", want: []string{}},
+		{name: "unbalanced closing brace", pattern: "a,b}", want: []string{"a,b}"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, complete := expandBraces(tt.pattern)
+			testhelper.DeepEqual(t, got, tt.want)
+
+			if !complete {
+				t.Errorf("got an incomplete expansion")
+			}
+		})
+	}
+}
+
+func TestFnmatch_gitlabDocumentation(t *testing.T) {
+	t.Parallel()
+
+	// examples from https://docs.gitlab.com/ee/user/project/codeowners/reference.html
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*", path: "/README.md", want: true},
+		{pattern: "*", path: "/.gitlab-ci.yml", want: true},
+		{pattern: "*", path: "/docs/.hidden/index.md", want: true},
+		{pattern: "*.rb", path: "/lib/song.rb", want: true},
+		{pattern: "\\#file_with_pound.rb", path: "/#file_with_pound.rb", want: true},
+		{pattern: "CODEOWNERS", path: "/.gitlab/CODEOWNERS", want: true},
+		{pattern: "README.md", path: "/README.md", want: true},
+		{pattern: "README.md", path: "/internal/README.md", want: true},
+		{pattern: "README.md", path: "/app/lib/README.md", want: true},
+		{pattern: "/README.md", path: "/README.md", want: true},
+		{pattern: "/README.md", path: "/internal/README.md", want: false},
+		{pattern: "internal/README.md", path: "/internal/README.md", want: true},
+		{pattern: "internal/README.md", path: "/docs/internal/README.md", want: true},
+		{pattern: "internal/README.md", path: "/docs/api/internal/README.md", want: true},
+		{pattern: "internal/README.md", path: "/README.md", want: false},
+		{pattern: "/docs/", path: "/docs/index.md", want: true},
+		{pattern: "/docs/", path: "/docs/projects/index.md", want: true},
+		{pattern: "/docs/", path: "/src/docs/index.md", want: false},
+		{pattern: "/docs/*", path: "/docs/index.md", want: true},
+		{pattern: "/docs/*", path: "/docs/projects/index.md", want: false},
+		{pattern: "/docs/**/*.md", path: "/docs/projects/index.md", want: true},
+		{pattern: "/docs/**/*.md", path: "/docs/development/index.md", want: true},
+		{pattern: "/docs/**/*.md", path: "/docs/index.md", want: true},
+		{pattern: "/docs/**/index.md", path: "/docs/projects/workflow/index.md", want: true},
+		{pattern: "lib/", path: "/lib/song.rb", want: true},
+		{pattern: "lib/", path: "/app/lib/nested/song.rb", want: true},
+		{pattern: "/config/", path: "/config/database.yml", want: true},
+		{pattern: "/config/", path: "/app/config/database.yml", want: false},
+		{pattern: "path\\ with\\ spaces/", path: "/path with spaces/file.md", want: true},
+		{pattern: "*.{rb,md}", path: "/lib/song.rb", want: true},
+		{pattern: "*.{rb,md}", path: "/docs/index.md", want: true},
+		{pattern: "*.{rb,md}", path: "/docs/index.txt", want: false},
+		{pattern: "/docs/*/index.md", path: "/docs/.hidden/index.md", want: true},
+		{pattern: "*.md", path: "/docs/.md", want: true},
+		{pattern: "/doc[sz]/", path: "/docz/index.md", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := newPattern(tt.pattern).match(tt.path, false); got != tt.want {
+			t.Errorf("pattern %s, path %s -> got %t, wanted %t", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFnmatch_expandBracesLimit(t *testing.T) {
+	t.Parallel()
+
+	got, complete := expandBraces(strings.Repeat("{a,b}", 32))
+	if len(got) != maxBraceExpansions || complete {
+		t.Errorf("got %d expansions and complete %t, wanted %d and false", len(got), complete, maxBraceExpansions)
+	}
+}
+
+func TestFnmatch_expandBracesWork(t *testing.T) {
+	t.Parallel()
+
+	// a short line with many braces used to take seconds for every path
+	input := strings.Repeat("{,}", 16000) + " @owner\n"
+	start := time.Now()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	file.GetRequiredApprovalsForFiles([]string{"/README.md", "/docs/index.md"})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v, wanted less than a second", elapsed)
+	}
+
+	if got := file.ParseErrors(); len(got) != 1 || !errors.Is(got[0], ErrPatternTooComplex) {
+		t.Errorf("got parse errors %v, wanted %v", got, ErrPatternTooComplex)
+	}
+}
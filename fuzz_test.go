@@ -0,0 +1,81 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzNewCodeOwnersFile(f *testing.F) {
+	seeds := []string{
+		"",
+		"* @group\n\n[Section name\ndocs/ @docs_group",
+		"[Documentation][2] @docs-team\ndocs/\nREADME.md\n\n^[Database] @database-team\nmodel/db/",
+		"[a]]b[\n[][4]\n[  ]\n[One][Two][Three]\n^[\n^",
+		"\\#file\\#with\\#pound.txt @a\nfile\\ with\\ spaces.txt @b",
+		"*.{md,txt @docs\n{a,b}{c,d}/ @e\n[!a-]/ @f\n**/**/x @g",
+		".*\\\\.go @user1 # comment\n!frontend/.*\\\\.js @user5\n(unclosed @x",
+		"docs/** Random(@@Writers, 2)\n*.css @bob LeastBusy(@@Frontend\n",
+		strings.Repeat("{,}", 12) + " @braces\n{a,{b,{c,d}}}/ @nested",
+		"\n\r\r",
+		"# crlf\r\n[Docs]\ndocs/ @a\r\n\r",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	paths := []string{"/", "/README.md", "/docs/a.md", "/.gitlab/CODEOWNERS", "/a/b/c/d.txt"}
+	dialects := []Dialect{DialectGitLab, DialectGitea, DialectBitbucket}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		for _, dialect := range dialects {
+			file, err := NewCodeOwnersFile(strings.NewReader(content), WithDialect(dialect))
+			if err != nil {
+				continue
+			}
+
+			file.GetRequiredApprovalsForFiles(paths)
+			file.GetRequiredApprovalsForFiles(paths, WithPathNormalization(), WithCaseInsensitiveMatching())
+
+			if dialect != DialectGitLab {
+				continue
+			}
+
+			// writing a parsed file and parsing it again must be stable
+			written := file.String()
+
+			reparsed, err := NewCodeOwnersFile(strings.NewReader(written))
+			if err != nil {
+				t.Fatalf("Failed to parse written file: %v", err)
+			}
+
+			if got := reparsed.String(); got != written {
+				t.Errorf("written file is not stable, got %q, wanted %q", got, written)
+			}
+		}
+	})
+}
+
+func FuzzParseSectionHeader(f *testing.F) {
+	for _, seed := range []string{"[Docs]", "^[Docs][2] @a", "[a]]b[", "[][]", "[", "^"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, header string) {
+		sec, err := parseSectionHeader(header)
+		if err == nil && strings.TrimSpace(sec.name) == "" {
+			t.Errorf("parsed section header %q without a name", header)
+		}
+	})
+}
+
+func FuzzFnmatch(f *testing.F) {
+	f.Add("/**/*.{md,txt}", "/docs/a.md")
+	f.Add("[!a-z]\\*?", "b*c")
+	f.Add("{{a,b},c", "a")
+
+	f.Fuzz(func(_ *testing.T, pattern, path string) {
+		fnmatch(pattern, path, false)
+		fnmatch(pattern, path, true)
+	})
+}
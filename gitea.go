@@ -0,0 +1,66 @@
+package gitlabcodeowners
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func parseGiteaFile(reader io.Reader, config parseConfig) (File, error) {
+	return parseRulesFile(reader, config, parseGiteaRule)
+}
+
+func parseGiteaRule(line string) (rule, bool, error) {
+	tokens := tokenizeGiteaLine(line)
+
+	// skip empty lines and lines only containing a comment
+	if len(tokens) == 0 {
+		return rule{}, false, nil
+	}
+
+	pattern, err := newRegexPattern(tokens[0])
+	if err != nil {
+		// Gitea silently ignores such rules, but the mistake is reported
+		// as parse error, which fails the parsing in strict mode
+		return rule{}, false, fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+	}
+
+	return newRule(pattern, tokens[1:]), true, nil
+}
+
+// tokenizeGiteaLine splits a line into whitespace separated tokens the same
+// way Gitea does. A `\` escapes the next character and an unescaped `#`
+// starts a comment which lasts until the end of the line.
+func tokenizeGiteaLine(line string) []string {
+	tokens := []string{}
+	token := strings.Builder{}
+	escape := false
+
+	for _, char := range strings.TrimSpace(line) {
+		switch {
+		case escape:
+			token.WriteRune(char)
+
+			escape = false
+		case char == '\\':
+			escape = true
+		case char == '#':
+			return appendToken(tokens, token.String())
+		case char == ' ' || char == '\t':
+			tokens = appendToken(tokens, token.String())
+			token.Reset()
+		default:
+			token.WriteRune(char)
+		}
+	}
+
+	return appendToken(tokens, token.String())
+}
+
+func appendToken(tokens []string, token string) []string {
+	if token == "" {
+		return tokens
+	}
+
+	return append(tokens, token)
+}
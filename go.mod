@@ -2,7 +2,4 @@ module github.com/chefe/gitlabcodeowners
 
 go 1.21.5
 
-require (
-	github.com/bmatcuk/doublestar/v4 v4.6.1 // indirect
-	github.com/go-test/deep v1.1.0 // indirect
-)
+require github.com/go-test/deep v1.1.0
@@ -0,0 +1,182 @@
+package gitlabcodeowners
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// initialLineBufferSize is the initial size of the buffer to read a line,
+// the buffer grows if a longer line is read.
+const initialLineBufferSize = 64 * 1024
+
+var (
+	utf8ByteOrderMark    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEByteOrderMark = []byte{0xFF, 0xFE}
+	utf16BEByteOrderMark = []byte{0xFE, 0xFF}
+)
+
+// lineScanner splits the content into lines of arbitrary length, unlike the
+// default scanner which fails for lines longer than 64 KiB. A byte order
+// mark is removed and content encoded as UTF-16 is converted to UTF-8.
+// Lines can end with `\n` or `\r\n`. The ending of the first line is
+// removed from all lines, while a line with another ending keeps it, so
+// files with mixed line endings can be written exactly as they were read.
+type lineScanner struct {
+	scanner *bufio.Scanner
+
+	// byteOrderMark is set if the content starts with an UTF-8 byte order mark.
+	byteOrderMark bool
+
+	// crlf is set if the first line ends with `\r\n`.
+	crlf bool
+
+	lines int
+}
+
+func newLineScanner(reader io.Reader) *lineScanner {
+	buffered := bufio.NewReader(reader)
+	lines := &lineScanner{scanner: nil, byteOrderMark: false, crlf: false, lines: 0}
+
+	// errors are ignored here, because they are returned again on the
+	// next read and therefore reported by the scanner
+	prefix, _ := buffered.Peek(len(utf8ByteOrderMark))
+
+	switch {
+	case bytes.HasPrefix(prefix, utf8ByteOrderMark):
+		lines.byteOrderMark = true
+		_, _ = buffered.Discard(len(utf8ByteOrderMark))
+
+		reader = buffered
+	case bytes.HasPrefix(prefix, utf16LEByteOrderMark), bytes.HasPrefix(prefix, utf16BEByteOrderMark):
+		reader = newUTF16Reader(buffered, bytes.HasPrefix(prefix, utf16BEByteOrderMark))
+	default:
+		reader = buffered
+	}
+
+	lines.scanner = bufio.NewScanner(reader)
+	lines.scanner.Buffer(make([]byte, 0, initialLineBufferSize), math.MaxInt)
+	lines.scanner.Split(lines.split)
+
+	return lines
+}
+
+func (l *lineScanner) split(data []byte, atEOF bool) (int, []byte, error) {
+	end := bytes.IndexByte(data, '\n')
+
+	switch {
+	case end < 0 && atEOF && len(data) > 0:
+		// the last line without a line ending
+		l.lines++
+
+		return len(data), data, nil
+	case end < 0:
+		return 0, nil, nil
+	}
+
+	crlf := end > 0 && data[end-1] == '\r'
+	if l.lines == 0 {
+		l.crlf = crlf
+	}
+
+	l.lines++
+
+	if crlf != l.crlf {
+		return end + 1, data[:end+1], nil
+	}
+
+	if crlf {
+		return end + 1, data[:end-1], nil
+	}
+
+	return end + 1, data[:end], nil
+}
+
+// withoutLineEnding removes the line ending which a line keeps if it differs
+// from the line ending of the first line.
+func withoutLineEnding(line string) string {
+	if !strings.HasSuffix(line, "\n") {
+		return line
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
+
+// Scan advances to the next line.
+func (l *lineScanner) Scan() bool {
+	return l.scanner.Scan()
+}
+
+// Text returns the current line without the line ending, unless it differs
+// from the line ending of the first line.
+func (l *lineScanner) Text() string {
+	return l.scanner.Text()
+}
+
+// Err returns the first error which occurred while reading.
+func (l *lineScanner) Err() error {
+	return l.scanner.Err() //nolint:wrapcheck // wrapped by the parser
+}
+
+// newUTF16Reader reads all content encoded as UTF-16 and returns a reader
+// for the content encoded as UTF-8 without the byte order mark.
+func newUTF16Reader(reader io.Reader, bigEndian bool) io.Reader {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return &failingReader{err: err}
+	}
+
+	units := make([]uint16, 0, len(content)/2) //nolint:gomnd // two bytes per unit
+	for i := 0; i+1 < len(content); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(content[i])<<8|uint16(content[i+1]))
+		} else {
+			units = append(units, uint16(content[i+1])<<8|uint16(content[i]))
+		}
+	}
+
+	// skip the byte order mark
+	return strings.NewReader(string(utf16.Decode(units[1:])))
+}
+
+// failingReader returns the same error for every read.
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
+
+// checkUTF8 returns a problem if the line contains invalid UTF-8.
+func checkUTF8(lineNumber int, raw string) *ParseError {
+	offset := invalidUTF8Offset(raw)
+	if offset < 0 {
+		return nil
+	}
+
+	return &ParseError{
+		Line:   lineNumber,
+		Column: utf8.RuneCountInString(raw[:offset]) + 1,
+		Text:   withoutLineEnding(raw),
+		Err:    ErrInvalidUTF8,
+	}
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid UTF-8
+// sequence in the line or -1 if the line is valid.
+func invalidUTF8Offset(line string) int {
+	for offset, char := range line {
+		if char == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(line[offset:]); size == 1 {
+				return offset
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,135 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestLines_byteOrderMarkAndLineEndings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "utf-8 with byte order mark",
+			content: "\xEF\xBB\xBF[Docs] @docs-team\ndocs/\n",
+			want:    "\xEF\xBB\xBF[Docs] @docs-team\ndocs/\n",
+		},
+		{
+			name:    "crlf line endings",
+			content: "[Docs] @docs-team\r\ndocs/\r\n",
+			want:    "[Docs] @docs-team\r\ndocs/\r\n",
+		},
+		{
+			name:    "mixed line endings starting with lf",
+			content: "[Docs] @docs-team\n# comment\r\ndocs/\n",
+			want:    "[Docs] @docs-team\n# comment\r\ndocs/\n",
+		},
+		{
+			name:    "mixed line endings starting with crlf",
+			content: "# comment\r\n[Docs] @docs-team\ndocs/\r\n",
+			want:    "# comment\r\n[Docs] @docs-team\ndocs/\r\n",
+		},
+		{
+			name:    "utf-16 little endian is written as utf-8",
+			content: "\xFF\xFE[\x00D\x00o\x00c\x00s\x00]\x00 \x00@\x00d\x00\n\x00d\x00o\x00c\x00s\x00/\x00\n\x00",
+			want:    "[Docs] @d\ndocs/\n",
+		},
+		{
+			name:    "utf-16 big endian is written as utf-8",
+			content: "\xFE\xFF\x00[\x00D\x00o\x00c\x00s\x00]\x00 \x00@\x00d\x00\n\x00d\x00o\x00c\x00s\x00/\x00\n",
+			want:    "[Docs] @d\ndocs/\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			if got := file.String(); got != tt.want {
+				t.Errorf("got %q, wanted %q", got, tt.want)
+			}
+
+			approvals := file.GetRequiredApprovalsForFile("/docs/README.md")
+			if _, ok := approvals["Docs"]; !ok {
+				t.Errorf("expected an approval for section Docs, got %v", approvals)
+			}
+		})
+	}
+}
+
+func TestLines_mixedLineEndingsOfChangedLines(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("[Docs] @docs-team\r\ndocs/ @old\nREADME.md @old\r\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	file.ReplaceOwner("@old", "@new")
+
+	// changed lines end like the first line of the file
+	if got, want := file.String(), "[Docs] @docs-team\r\ndocs/ @new\r\nREADME.md @new\r\n"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestLines_invalidUTF8(t *testing.T) {
+	t.Parallel()
+
+	content := "[Docs] @docs-team\ndocs/\xFFfile.md\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	want := []*ParseError{{Line: 2, Column: 6, Text: "docs/\xFFfile.md", Err: ErrInvalidUTF8}}
+	testhelper.DeepEqual(t, file.ParseErrors(), want)
+
+	_, err = NewCodeOwnersFile(strings.NewReader(content), WithStrictParsing())
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("got error %v, wanted %v", err, ErrInvalidUTF8)
+	}
+}
+
+func TestLines_invalidUTF8Offset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+		want int
+	}{
+		{name: "valid ascii", line: "docs/ @docs-team", want: -1},
+		{name: "valid multibyte", line: "dokumentä/ @docs-team", want: -1},
+		{name: "encoded replacement character", line: "�.md", want: -1},
+		{name: "invalid byte", line: "ä\xFF.md", want: 2},
+		{name: "truncated sequence", line: "docs/\xC3", want: 5},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := invalidUTF8Offset(tt.line); got != tt.want {
+				t.Errorf("got %d, wanted %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package gitlabcodeowners
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrUnknownCheck is returned if the lint configuration refers to a
+	// check which does not exist.
+	ErrUnknownCheck = errors.New("unknown check")
+
+	// ErrInvalidSeverity is returned if the lint configuration contains an
+	// unknown severity.
+	ErrInvalidSeverity = errors.New("invalid severity")
+
+	// ErrInvalidLintConfig is returned if the lint configuration can not be read.
+	ErrInvalidLintConfig = errors.New("invalid lint configuration")
+)
+
+// Severity describes how serious a problem found by a check is.
+type Severity string
+
+const (
+	// SeverityError is used for problems which break the ownership rules.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for problems which are likely mistakes.
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo is used for problems of style.
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic describes a problem found by a check.
+type Diagnostic struct {
+	// Check is the name of the check which found the problem.
+	Check string
+
+	Severity Severity
+
+	// Line is the line of the problem starting at 1, it is 0 if the problem
+	// is not related to a single line.
+	Line int
+
+	// Column is the column of the problem starting at 1, it is 0 if the
+	// problem concerns the whole line.
+	Column int
+
+	Message string
+
+	// Fix is the suggested fix of the problem, it is nil if there is none.
+	Fix *Fix
+}
+
+// Fix is a suggested edit which replaces the text of a line between
+// `Column` and `EndColumn` by `Text`. If `Column` is 0 the whole line
+// including its line break is replaced, so an empty `Text` deletes it.
+type Fix struct {
+	Description string
+
+	// Line is the line to edit starting at 1.
+	Line int
+
+	// Column and EndColumn are the columns of the replaced text starting
+	// at 1, `EndColumn` is the first column after it.
+	Column    int
+	EndColumn int
+
+	Text string
+}
+
+// Check is a pluggable analyzer which reports problems in a parsed file.
+type Check struct {
+	// Name identifies the check in the lint configuration.
+	Name string
+
+	// Doc describes the problems reported by the check.
+	Doc string
+
+	// Severity is the severity of the reported problems unless it is
+	// changed in the lint configuration.
+	Severity Severity
+
+	// Enabled is set if the check runs unless it is disabled in the lint
+	// configuration.
+	Enabled bool
+
+	// Run reports the problems in the file of the pass.
+	Run func(pass *Pass)
+}
+
+// Pass contains the file analyzed by a check and collects its problems.
+type Pass struct {
+	// File is the analyzed file.
+	File File
+
+	// Now is the time the file is analyzed at, for example to find
+	// expired entries.
+	Now time.Time
+
+	check       Check
+	severity    Severity
+	diagnostics []Diagnostic
+}
+
+// Report adds a problem found at the given line and column.
+func (p *Pass) Report(line, column int, format string, args ...any) {
+	p.report(line, column, nil, format, args...)
+}
+
+// ReportWithFix adds a problem found at the given line and column together
+// with a suggested fix.
+func (p *Pass) ReportWithFix(line, column int, fix Fix, format string, args ...any) {
+	p.report(line, column, &fix, format, args...)
+}
+
+func (p *Pass) report(line, column int, fix *Fix, format string, args ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Check:    p.check.Name,
+		Severity: p.severity,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+		Fix:      fix,
+	})
+}
+
+// LintConfig selects the checks of a `Linter` similar to `golangci-lint`.
+type LintConfig struct {
+	// DisableAll disables all checks which are not explicitly enabled.
+	DisableAll bool `json:"disable-all"`
+
+	// Enable contains the names of the checks to enable.
+	Enable []string `json:"enable"`
+
+	// Disable contains the names of the checks to disable.
+	Disable []string `json:"disable"`
+
+	// Severity changes the severity of the checks given by their names.
+	Severity map[string]Severity `json:"severity"`
+}
+
+// ReadLintConfig reads a lint configuration in the JSON format, for example
+// `{"enable": ["unsorted-rules"], "severity": {"invalid-owner": "error"}}`.
+func ReadLintConfig(reader io.Reader) (LintConfig, error) {
+	config := LintConfig{DisableAll: false, Enable: nil, Disable: nil, Severity: nil}
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&config); err != nil {
+		return LintConfig{}, fmt.Errorf("%w: %w", ErrInvalidLintConfig, err) //nolint:exhaustruct // not used on error
+	}
+
+	return config, nil
+}
+
+// Linter runs the enabled checks on a file.
+type Linter struct {
+	checks []Check
+}
+
+// NewLinter returns a linter which runs the checks selected by the
+// configuration. If no checks are given, `DefaultChecks` are used.
+func NewLinter(config LintConfig, checks ...Check) (*Linter, error) {
+	if len(checks) == 0 {
+		checks = DefaultChecks()
+	}
+
+	enabled := map[string]bool{}
+
+	for _, check := range checks {
+		enabled[check.Name] = check.Enabled && !config.DisableAll
+	}
+
+	for _, names := range [][]string{config.Enable, config.Disable} {
+		for _, name := range names {
+			if _, ok := enabled[name]; !ok {
+				return nil, fmt.Errorf("%w: '%s'", ErrUnknownCheck, name)
+			}
+		}
+	}
+
+	for name, severity := range config.Severity {
+		if _, ok := enabled[name]; !ok {
+			return nil, fmt.Errorf("%w: '%s'", ErrUnknownCheck, name)
+		}
+
+		if severity != SeverityError && severity != SeverityWarning && severity != SeverityInfo {
+			return nil, fmt.Errorf("%w: '%s' for check '%s'", ErrInvalidSeverity, severity, name)
+		}
+	}
+
+	for _, name := range config.Enable {
+		enabled[name] = true
+	}
+
+	for _, name := range config.Disable {
+		enabled[name] = false
+	}
+
+	linter := &Linter{checks: []Check{}}
+
+	for _, check := range checks {
+		if !enabled[check.Name] {
+			continue
+		}
+
+		if severity, ok := config.Severity[check.Name]; ok {
+			check.Severity = severity
+		}
+
+		linter.checks = append(linter.checks, check)
+	}
+
+	return linter, nil
+}
+
+// Checks returns the names of the enabled checks.
+func (l *Linter) Checks() []string {
+	names := make([]string, 0, len(l.checks))
+
+	for _, check := range l.checks {
+		names = append(names, check.Name)
+	}
+
+	return names
+}
+
+// Lint runs all enabled checks on the file and returns the problems ordered
+// by their position.
+func (l *Linter) Lint(file File) []Diagnostic {
+	return l.LintAt(file, time.Now())
+}
+
+// LintAt runs all enabled checks like `Lint`, but analyzes the file at the
+// given time.
+func (l *Linter) LintAt(file File, now time.Time) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, check := range l.checks {
+		pass := &Pass{File: file, Now: now, check: check, severity: check.Severity, diagnostics: nil}
+		check.Run(pass)
+
+		diagnostics = append(diagnostics, pass.diagnostics...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+
+	return diagnostics
+}
@@ -0,0 +1,282 @@
+package gitlabcodeowners
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultChecks returns all checks which are provided by this package.
+func DefaultChecks() []Check {
+	return []Check{
+		{
+			Name:     "parse-error",
+			Doc:      "reports lines which could not be parsed",
+			Severity: SeverityError,
+			Enabled:  true,
+			Run:      checkParseErrors,
+		},
+		{
+			Name:     "duplicate-pattern",
+			Doc:      "reports patterns used more than once in a section, where only the last rule applies",
+			Severity: SeverityWarning,
+			Enabled:  true,
+			Run:      checkDuplicatePatterns,
+		},
+		{
+			Name:     "invalid-owner",
+			Doc:      "reports owners which are neither prefixed with `@` nor a valid email address",
+			Severity: SeverityError,
+			Enabled:  true,
+			Run:      checkInvalidOwners,
+		},
+		{
+			Name:     "insufficient-owners",
+			Doc:      "reports rules of required sections with fewer owners than approvals, where groups count as one owner",
+			Severity: SeverityWarning,
+			Enabled:  true,
+			Run:      checkInsufficientOwners,
+		},
+		{
+			Name:     "unsorted-rules",
+			Doc:      "reports rules which are not sorted by their pattern within a section",
+			Severity: SeverityInfo,
+			Enabled:  false,
+			Run:      checkUnsortedRules,
+		},
+		{
+			Name:     "conflicting-duplicate-sections",
+			Doc:      "reports duplicated section headers with another approval count than the first header",
+			Severity: SeverityWarning,
+			Enabled:  true,
+			Run:      checkConflictingDuplicateSections,
+		},
+		{
+			Name:     "expired-entry",
+			Doc:      "reports sections and rules whose `@expires` date has passed or is invalid",
+			Severity: SeverityWarning,
+			Enabled:  true,
+			Run:      checkExpiredEntries,
+		},
+	}
+}
+
+func checkParseErrors(pass *Pass) {
+	for _, parseErr := range pass.File.ParseErrors() {
+		pass.Report(parseErr.Line, parseErr.Column, "%v", parseErr.Err)
+	}
+}
+
+func checkDuplicatePatterns(pass *Pass) {
+	for _, sec := range pass.File.sections {
+		lines := map[string]int{}
+
+		for _, r := range sec.rules {
+			if line, ok := lines[r.pattern.value]; ok {
+				fix := Fix{Description: fmt.Sprintf("remove the rule on line %d", line), Line: line, Column: 0, EndColumn: 0, Text: ""}
+				pass.ReportWithFix(
+					r.src.line, 0, fix, "pattern '%s' is already used on line %d, only the last rule applies", r.pattern.value, line,
+				)
+			}
+
+			lines[r.pattern.value] = r.src.line
+		}
+	}
+}
+
+func checkInvalidOwners(pass *Pass) {
+	report := func(src source, owners []string) {
+		for _, owner := range owners {
+			if isValidOwner(owner) {
+				continue
+			}
+
+			message := "owner '%s' is neither prefixed with '@' nor a valid email address"
+			column := columnOf(src.raw, owner)
+
+			// an owner without any `@` is most likely a user or group name
+			if column == 0 || strings.Contains(owner, "@") {
+				pass.Report(src.line, column, message, owner)
+
+				continue
+			}
+
+			fix := Fix{
+				Description: fmt.Sprintf("prefix '%s' with '@'", owner),
+				Line:        src.line,
+				Column:      column,
+				EndColumn:   column + utf8.RuneCountInString(owner),
+				Text:        "@" + owner,
+			}
+			pass.ReportWithFix(src.line, column, fix, message, owner)
+		}
+	}
+
+	for _, sec := range pass.File.sections {
+		report(sec.src, sec.owners)
+
+		for _, r := range sec.rules {
+			report(r.src, r.owners)
+		}
+	}
+}
+
+func isValidOwner(owner string) bool {
+	if strings.HasPrefix(owner, "@") {
+		return len(strings.TrimLeft(owner, "@")) > 0
+	}
+
+	address, err := mail.ParseAddress(owner)
+
+	return err == nil && address.Address == owner
+}
+
+func checkInsufficientOwners(pass *Pass) {
+	for _, sec := range pass.File.sections {
+		if sec.optional || sec.approvals <= 1 {
+			continue
+		}
+
+		usesDefaultOwners := false
+
+		// every rule is approved by its own owners or the default owners
+		// of the section, so they are checked separately
+		for _, r := range sec.rules {
+			if len(r.owners) == 0 {
+				usesDefaultOwners = true
+
+				continue
+			}
+
+			if count := distinctOwners(r.owners); count < sec.approvals {
+				pass.Report(
+					r.src.line, 0, "rule '%s' in section '%s' requires %d approvals, but has only %d owners",
+					r.pattern.value, sec.name, sec.approvals, count,
+				)
+			}
+		}
+
+		if count := distinctOwners(sec.owners); usesDefaultOwners && count > 0 && count < sec.approvals {
+			pass.Report(sec.src.line, 0, "section '%s' requires %d approvals, but has only %d owners", sec.name, sec.approvals, count)
+		}
+	}
+}
+
+// distinctOwners returns the number of owners, which are compared case-insensitively.
+func distinctOwners(owners []string) int {
+	distinct := map[string]bool{}
+
+	for _, owner := range owners {
+		distinct[strings.ToLower(owner)] = true
+	}
+
+	return len(distinct)
+}
+
+func checkUnsortedRules(pass *Pass) {
+	for _, sec := range pass.File.sections {
+		for i := 1; i < len(sec.rules); i++ {
+			previous, current := sec.rules[i-1], sec.rules[i]
+
+			if current.pattern.value < previous.pattern.value {
+				pass.Report(current.src.line, 0, "rule '%s' should be sorted before '%s'", current.pattern.value, previous.pattern.value)
+			}
+		}
+	}
+}
+
+func checkConflictingDuplicateSections(pass *Pass) {
+	first := map[string]sectionHeaderOccurrence{}
+
+	for _, header := range pass.File.sectionHeaderOccurrences() {
+		key := strings.ToLower(header.section.name)
+
+		original, ok := first[key]
+		if !ok {
+			first[key] = header
+
+			continue
+		}
+
+		if header.section.approvals != original.section.approvals || header.section.optional != original.section.optional {
+			pass.Report(
+				header.line, 0, "section '%s' (approvals: %s) is merged into the section on line %d (approvals: %s), only the first header applies",
+				header.section.name, approvalsText(header.section), original.line, approvalsText(original.section),
+			)
+		}
+	}
+}
+
+func checkExpiredEntries(pass *Pass) {
+	for _, entry := range pass.File.ExpiredEntries(pass.Now) {
+		name := "rule '" + entry.Pattern + "'"
+		if entry.Pattern == "" {
+			name = "section '" + entry.Section + "'"
+		}
+
+		if entry.Err != nil {
+			pass.Report(entry.Line, 0, "%s has an invalid @expires annotation: %v", name, entry.Err)
+
+			continue
+		}
+
+		pass.Report(entry.Line, 0, "%s expired on %s", name, entry.Expires.Format(time.DateOnly))
+	}
+}
+
+// sectionHeaderOccurrence is a section header read from a file.
+type sectionHeaderOccurrence struct {
+	section section
+	line    int
+}
+
+// sectionHeaderOccurrences returns all section headers ordered by their line
+// in the written file, including the headers of sections which were merged
+// into a previous section with the same name or which have no rules. The
+// lines are the lines of the file as it was read, unless it was modified.
+func (f File) sectionHeaderOccurrences() []sectionHeaderOccurrence {
+	headers := []sectionHeaderOccurrence{}
+	lineNumber := 0
+
+	add := func(lines []string) {
+		for _, raw := range lines {
+			lineNumber++
+
+			line := strings.TrimSpace(raw)
+			if !strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "^[") {
+				continue
+			}
+
+			if sec, err := parseSectionHeaderLine(line); err == nil {
+				headers = append(headers, sectionHeaderOccurrence{section: sec, line: lineNumber})
+			}
+		}
+	}
+
+	for _, item := range f.writerItems() {
+		add(item.lines)
+	}
+
+	add(f.trailing)
+
+	return headers
+}
+
+// columnOf returns the column of the first whitespace separated field in the
+// line which equals the token starting at 1, it returns 0 if there is none.
+func columnOf(line, token string) int {
+	offset := 0
+
+	for _, field := range strings.Fields(line) {
+		index := offset + strings.Index(line[offset:], field)
+		offset = index + len(field)
+
+		if field == token {
+			return utf8.RuneCountInString(line[:index]) + 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,167 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestLintChecks_DefaultChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		check   string
+		content string
+		want    []Diagnostic
+	}{
+		{
+			name:    "parse error",
+			check:   "parse-error",
+			content: "* @a\n[Docs]]\n",
+			want: []Diagnostic{
+				{Line: 2, Column: 7, Message: "no matching bracket count"},
+			},
+		},
+		{
+			name:    "duplicate pattern",
+			check:   "duplicate-pattern",
+			content: "*.md @a\n[Docs]\n*.md @b\n[Code]\n*.go @c\n[docs]\n*.md @d\n",
+			want: []Diagnostic{
+				{
+					Line:    7,
+					Message: "pattern '*.md' is already used on line 3, only the last rule applies",
+					Fix:     &Fix{Description: "remove the rule on line 3", Line: 3},
+				},
+			},
+		},
+		{
+			name:    "invalid owner",
+			check:   "invalid-owner",
+			content: "[Docs] docs-team\n*.md @a a@example.com @ alice\n",
+			want: []Diagnostic{
+				{
+					Line:    1,
+					Column:  8,
+					Message: "owner 'docs-team' is neither prefixed with '@' nor a valid email address",
+					Fix:     &Fix{Description: "prefix 'docs-team' with '@'", Line: 1, Column: 8, EndColumn: 17, Text: "@docs-team"},
+				},
+				{Line: 2, Column: 23, Message: "owner '@' is neither prefixed with '@' nor a valid email address"},
+				{
+					Line:    2,
+					Column:  25,
+					Message: "owner 'alice' is neither prefixed with '@' nor a valid email address",
+					Fix:     &Fix{Description: "prefix 'alice' with '@'", Line: 2, Column: 25, EndColumn: 30, Text: "@alice"},
+				},
+			},
+		},
+		{
+			name:  "insufficient owners",
+			check: "insufficient-owners",
+			content: "[Docs][3] @a\n*.md @b @A\n[Code][2]\n*.go @b @c\n^[Optional][5]\n* @a\n" +
+				"[Security][2] @a @b @c\n*.go\n*.pem @sec\n[Ops][3] @a @b\n/ops/\n",
+			want: []Diagnostic{
+				{Line: 2, Message: "rule '*.md' in section 'Docs' requires 3 approvals, but has only 2 owners"},
+				{Line: 9, Message: "rule '*.pem' in section 'Security' requires 2 approvals, but has only 1 owners"},
+				{Line: 10, Message: "section 'Ops' requires 3 approvals, but has only 2 owners"},
+			},
+		},
+		{
+			name:    "unsorted rules",
+			check:   "unsorted-rules",
+			content: "[Docs] @a\n/docs/\n*.md\n[Code] @b\n*.go\n/cmd/\n",
+			want: []Diagnostic{
+				{Line: 3, Message: "rule '*.md' should be sorted before '/docs/'"},
+			},
+		},
+		{
+			name:    "conflicting duplicate sections",
+			check:   "conflicting-duplicate-sections",
+			content: "[Docs][2] @a\n*.md\n[Code]\n*.go @b\n[docs][2]\n*.txt\n^[DOCS]\n*.rst\n[Docs][3]\n",
+			want: []Diagnostic{
+				{
+					Line:    7,
+					Message: "section 'DOCS' (approvals: optional) is merged into the section on line 1 (approvals: 2), only the first header applies",
+				},
+				{
+					Line:    9,
+					Message: "section 'Docs' (approvals: 3) is merged into the section on line 1 (approvals: 2), only the first header applies",
+				},
+			},
+		},
+		{
+			name:    "expired entry",
+			check:   "expired-entry",
+			content: "# @expires 2026-01-01\n[Docs] @a\n# @expires soon\n*.md\n",
+			want: []Diagnostic{
+				{Line: 2, Message: "section 'Docs' expired on 2026-01-01"},
+				{
+					Line: 4,
+					Message: "rule '*.md' has an invalid @expires annotation: " +
+						"invalid expiry date: parsing time \"soon\" as \"2006-01-02\": cannot parse \"soon\" as \"2006\"",
+				},
+			},
+		},
+	}
+
+	checks := map[string]Check{}
+	for _, check := range DefaultChecks() {
+		checks[check.Name] = check
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			check := checks[tt.check]
+			check.Enabled = true
+
+			linter, err := NewLinter(LintConfig{}, check)
+			if err != nil {
+				t.Fatalf("Failed to create linter: %v", err)
+			}
+
+			for i := range tt.want {
+				tt.want[i].Check = check.Name
+				tt.want[i].Severity = check.Severity
+			}
+
+			got := linter.LintAt(file, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestLintChecks_sectionHeaderOccurrences(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("[Docs] @a\n*.md\n\n# moved\n*.txt\n[docs][2]\n*.rst\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	// the lines in front of the removed rule are moved to the next rule
+	if err := file.RemoveRule("Docs", "*.txt"); err != nil {
+		t.Fatalf("Failed to remove rule: %v", err)
+	}
+
+	lines := []int{}
+	for _, header := range file.sectionHeaderOccurrences() {
+		lines = append(lines, header.line)
+	}
+
+	testhelper.DeepEqual(t, lines, []int{1, 4})
+
+	if got := strings.Split(file.String(), "\n")[3]; got != "[docs][2]" {
+		t.Errorf("got line 4 %q, wanted the merged header", got)
+	}
+}
@@ -0,0 +1,132 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestLint_ReadLintConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := ReadLintConfig(strings.NewReader(`{
+		"disable-all": true,
+		"enable": ["unsorted-rules"],
+		"disable": ["parse-error"],
+		"severity": {"unsorted-rules": "error"}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	testhelper.DeepEqual(t, config, LintConfig{
+		DisableAll: true,
+		Enable:     []string{"unsorted-rules"},
+		Disable:    []string{"parse-error"},
+		Severity:   map[string]Severity{"unsorted-rules": SeverityError},
+	})
+
+	if _, err := ReadLintConfig(strings.NewReader(`{"enabled": []}`)); !errors.Is(err, ErrInvalidLintConfig) {
+		t.Errorf("got error %v, wanted %v", err, ErrInvalidLintConfig)
+	}
+}
+
+func TestLint_NewLinter(t *testing.T) {
+	t.Parallel()
+
+	defaults := []string{
+		"parse-error", "duplicate-pattern", "invalid-owner", "insufficient-owners",
+		"conflicting-duplicate-sections", "expired-entry",
+	}
+
+	tests := []struct {
+		name    string
+		config  LintConfig
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "default checks",
+			config: LintConfig{},
+			want:   defaults,
+		},
+		{
+			name:   "enable and disable checks",
+			config: LintConfig{Enable: []string{"unsorted-rules"}, Disable: []string{"expired-entry"}},
+			want: []string{
+				"parse-error", "duplicate-pattern", "invalid-owner", "insufficient-owners",
+				"unsorted-rules", "conflicting-duplicate-sections",
+			},
+		},
+		{
+			name:   "disable all",
+			config: LintConfig{DisableAll: true, Enable: []string{"invalid-owner"}},
+			want:   []string{"invalid-owner"},
+		},
+		{
+			name:    "unknown check",
+			config:  LintConfig{Disable: []string{"unknown"}},
+			wantErr: ErrUnknownCheck,
+		},
+		{
+			name:    "unknown check with severity",
+			config:  LintConfig{Severity: map[string]Severity{"unknown": SeverityError}},
+			wantErr: ErrUnknownCheck,
+		},
+		{
+			name:    "invalid severity",
+			config:  LintConfig{Severity: map[string]Severity{"invalid-owner": "fatal"}},
+			wantErr: ErrInvalidSeverity,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			linter, err := NewLinter(tt.config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, wanted %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				testhelper.DeepEqual(t, linter.Checks(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLint_customCheck(t *testing.T) {
+	t.Parallel()
+
+	check := Check{
+		Name:     "no-catch-all",
+		Doc:      "reports a catch all rule",
+		Severity: SeverityWarning,
+		Enabled:  true,
+		Run: func(pass *Pass) {
+			for path, approval := range pass.File.GetRequiredApprovalsForFile("/a/b/c") {
+				pass.Report(0, 0, "section '%s' has a catch all rule '%s'", path, approval.Pattern)
+			}
+		},
+	}
+
+	linter, err := NewLinter(LintConfig{Severity: map[string]Severity{"no-catch-all": SeverityError}}, check)
+	if err != nil {
+		t.Fatalf("Failed to create linter: %v", err)
+	}
+
+	file, err := NewCodeOwnersFile(strings.NewReader("* @all\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	testhelper.DeepEqual(t, linter.LintAt(file, time.Time{}), []Diagnostic{
+		{Check: "no-catch-all", Severity: SeverityError, Message: "section '' has a catch all rule '*'"},
+	})
+}
@@ -0,0 +1,607 @@
+package gitlabcodeowners
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// LanguageServerOption configures a `LanguageServer`.
+type LanguageServerOption func(*LanguageServer)
+
+// WithMembership returns an option which completes owners from the users and
+// groups of the membership snapshot.
+func WithMembership(membership Membership) LanguageServerOption {
+	return func(server *LanguageServer) {
+		server.membership = membership
+	}
+}
+
+// WithWorkspace returns an option which uses the file system as workspace to
+// complete paths and to count the files matched by a pattern. By default
+// the root directory sent by the client is used.
+func WithWorkspace(fsys fs.FS) LanguageServerOption {
+	return func(server *LanguageServer) {
+		server.workspace = fsys
+	}
+}
+
+// WithLinter returns an option which publishes the problems found by the
+// linter as diagnostics. By default the enabled `DefaultChecks` are run.
+func WithLinter(linter *Linter) LanguageServerOption {
+	return func(server *LanguageServer) {
+		server.linter = linter
+	}
+}
+
+// LanguageServer is a language server for `CODEOWNERS` files in the Gitlab
+// syntax, which communicates with the client using the Language Server
+// Protocol over a reader and a writer like stdin and stdout. It publishes
+// diagnostics while typing and provides hover, completion, go to definition
+// and formatting.
+type LanguageServer struct {
+	membership Membership
+	linter     *Linter
+	workspace  fs.FS
+
+	// files contains the paths of all files in the workspace, it is nil
+	// until the workspace is read.
+	files []string
+
+	// documents maps the URIs of the open documents to their content.
+	documents map[string]string
+
+	// notifications are written after the response of the current message.
+	notifications []rpcNotification
+}
+
+// NewLanguageServer returns a language server configured by the options.
+func NewLanguageServer(options ...LanguageServerOption) *LanguageServer {
+	server := &LanguageServer{
+		membership:    Membership{Users: nil, Groups: nil},
+		linter:        nil,
+		workspace:     nil,
+		files:         nil,
+		documents:     map[string]string{},
+		notifications: nil,
+	}
+
+	for _, option := range options {
+		option(server)
+	}
+
+	if server.linter == nil {
+		// the default configuration is always valid
+		server.linter, _ = NewLinter(LintConfig{DisableAll: false, Enable: nil, Disable: nil, Severity: nil})
+	}
+
+	return server
+}
+
+// Serve reads messages from the reader and writes the responses to the
+// writer until the client sends the `exit` notification or the reader is
+// closed.
+func (s *LanguageServer) Serve(reader io.Reader, writer io.Writer) error {
+	buffered := bufio.NewReader(reader)
+
+	for {
+		content, err := readMessage(buffered)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		var message rpcMessage
+		if err := json.Unmarshal(content, &message); err != nil {
+			response := rpcResponse{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage("null"),
+				Result:  nil,
+				Error:   &rpcError{Code: rpcParseError, Message: err.Error()},
+			}
+			if err := writeMessage(writer, response); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if message.Method == "exit" {
+			return nil
+		}
+
+		if err := s.respond(writer, message); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *LanguageServer) respond(writer io.Writer, message rpcMessage) error {
+	result, rpcErr := s.handle(message.Method, message.Params)
+
+	// notifications do not get a response, even if they are not supported
+	if len(message.ID) > 0 {
+		response := rpcResponse{JSONRPC: "2.0", ID: message.ID, Result: nil, Error: rpcErr}
+
+		if rpcErr == nil {
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("failed to encode result: %w", err)
+			}
+
+			response.Result = encoded
+		}
+
+		if err := writeMessage(writer, response); err != nil {
+			return err
+		}
+	}
+
+	for _, notification := range s.notifications {
+		if err := writeMessage(writer, notification); err != nil {
+			return err
+		}
+	}
+
+	s.notifications = nil
+
+	return nil
+}
+
+func (s *LanguageServer) handle(method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		return decodeAndHandle(params, s.initialize)
+	case "initialized", "$/cancelRequest", "textDocument/didSave":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "workspace/didChangeWatchedFiles":
+		s.files = nil
+
+		return nil, nil
+	case "textDocument/didOpen":
+		return decodeAndHandle(params, func(params lspDidOpenParams) any {
+			s.update(params.TextDocument.URI, params.TextDocument.Text)
+
+			return nil
+		})
+	case "textDocument/didChange":
+		return decodeAndHandle(params, func(params lspDidChangeParams) any {
+			// the full content is sent with each change
+			if len(params.ContentChanges) > 0 {
+				s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+			}
+
+			return nil
+		})
+	case "textDocument/didClose":
+		return decodeAndHandle(params, func(params lspDidCloseParams) any {
+			delete(s.documents, params.TextDocument.URI)
+			s.publishDiagnostics(params.TextDocument.URI, []lspDiagnostic{})
+
+			return nil
+		})
+	case "textDocument/hover":
+		return decodeAndHandle(params, s.hover)
+	case "textDocument/completion":
+		return decodeAndHandle(params, s.completion)
+	case "textDocument/definition":
+		return decodeAndHandle(params, s.definition)
+	case "textDocument/formatting":
+		return decodeAndHandle(params, s.formatting)
+	}
+
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method '%s' is not supported", method)}
+}
+
+// decodeAndHandle decodes the parameters and passes them to the handler.
+func decodeAndHandle[T any](params json.RawMessage, handler func(T) any) (any, *rpcError) {
+	var decoded T
+
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &decoded); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+	}
+
+	return handler(decoded), nil
+}
+
+func (s *LanguageServer) initialize(params lspInitializeParams) any {
+	if s.workspace == nil {
+		if root, ok := uriPath(params.RootURI); ok {
+			s.workspace = os.DirFS(root)
+		}
+	}
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":           lspTextDocumentSyncFull,
+			"hoverProvider":              true,
+			"completionProvider":         map[string]any{"triggerCharacters": []string{"@", "/"}},
+			"definitionProvider":         true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]any{"name": "gitlabcodeowners"},
+	}
+}
+
+func (s *LanguageServer) update(uri, text string) {
+	s.documents[uri] = text
+	s.publishDiagnostics(uri, s.diagnostics(text))
+}
+
+func (s *LanguageServer) publishDiagnostics(uri string, diagnostics []lspDiagnostic) {
+	s.notifications = append(s.notifications, rpcNotification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  lspPublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+// diagnostics returns the problems found in the document by the linter.
+func (s *LanguageServer) diagnostics(text string) []lspDiagnostic {
+	lines := splitLines(text)
+
+	var diagnostics []Diagnostic
+
+	file, err := NewCodeOwnersFile(strings.NewReader(text))
+	if err != nil {
+		// a line which fails the whole file is reported as parse error
+		line, column, message := 0, 0, err.Error()
+
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			line, column, message = parseErr.Line, parseErr.Column, parseErr.Err.Error()
+		}
+
+		diagnostics = []Diagnostic{{Check: "parse-error", Severity: SeverityError, Line: line, Column: column, Message: message, Fix: nil}}
+	} else {
+		diagnostics = s.linter.Lint(file)
+	}
+
+	converted := make([]lspDiagnostic, 0, len(diagnostics))
+
+	for _, diagnostic := range diagnostics {
+		converted = append(converted, lspDiagnostic{
+			Range:    lineRange(lines, diagnostic.Line, diagnostic.Column),
+			Severity: lspSeverity(diagnostic.Severity),
+			Code:     diagnostic.Check,
+			Source:   "gitlabcodeowners",
+			Message:  diagnostic.Message,
+		})
+	}
+
+	return converted
+}
+
+func lspSeverity(severity Severity) int {
+	switch severity {
+	case SeverityError:
+		return lspSeverityError
+	case SeverityWarning:
+		return lspSeverityWarning
+	case SeverityInfo:
+		return lspSeverityInformation
+	}
+
+	return lspSeverityInformation
+}
+
+// document returns the lines of an open document and the parsed file, ok is
+// false if the document is not open or can not be parsed.
+func (s *LanguageServer) document(uri string) (lines []string, file File, ok bool) { //nolint:nonamedreturns,lll // name the returned values
+	text, open := s.documents[uri]
+	if !open {
+		return nil, emptyFile(), false
+	}
+
+	file, err := NewCodeOwnersFile(strings.NewReader(text))
+	if err != nil {
+		return splitLines(text), emptyFile(), false
+	}
+
+	return splitLines(text), file, true
+}
+
+// hover shows the normalized glob of the pattern of a rule and the number of
+// files in the workspace which it matches.
+func (s *LanguageServer) hover(params lspTextDocumentPositionParams) any {
+	lines, file, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+
+	for _, sec := range file.sections {
+		for _, r := range sec.rules {
+			if r.src.line != params.Position.Line+1 {
+				continue
+			}
+
+			content := fmt.Sprintf("**Pattern** `%s`\n\nNormalized glob: `%s`", r.pattern.value, r.pattern.normalized)
+
+			if files := s.workspaceFiles(); files != nil {
+				count := 0
+
+				for _, path := range files {
+					if r.pattern.match(path, false) {
+						count++
+					}
+				}
+
+				content += fmt.Sprintf("\n\nMatches %d of %d files in the workspace.", count, len(files))
+			}
+
+			return lspHover{
+				Contents: lspMarkupContent{Kind: "markdown", Value: content},
+				Range:    lineRange(lines, r.src.line, columnOf(r.src.raw, strings.Fields(r.src.raw)[0])),
+			}
+		}
+	}
+
+	return nil
+}
+
+// completion completes the paths of the workspace for the pattern of a rule
+// and the owners of the membership snapshot for all other fields.
+func (s *LanguageServer) completion(params lspTextDocumentPositionParams) any {
+	items := []lspCompletionItem{}
+
+	text, open := s.documents[params.TextDocument.URI]
+	lines := splitLines(text)
+
+	if !open || params.Position.Line >= len(lines) {
+		return items
+	}
+
+	line := lines[params.Position.Line]
+	prefix := line[:byteOffset(line, params.Position.Character)]
+
+	trimmed := strings.TrimSpace(prefix)
+	if strings.HasPrefix(trimmed, "#") {
+		return items
+	}
+
+	start := strings.LastIndexAny(prefix, " \t") + 1
+	word := prefix[start:]
+	first := strings.TrimSpace(prefix[:start]) == ""
+	header := strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "^[")
+
+	edit := lspRange{
+		Start: lspPosition{Line: params.Position.Line, Character: utf16Length(prefix[:start])},
+		End:   params.Position,
+	}
+
+	add := func(label string, kind int) {
+		items = append(items, lspCompletionItem{Label: label, Kind: kind, TextEdit: lspTextEdit{Range: edit, NewText: label}})
+	}
+
+	switch {
+	case first && header:
+	case first && !strings.HasPrefix(word, "@"):
+		for _, path := range s.workspacePaths() {
+			if !strings.HasPrefix(word, "/") {
+				path = strings.TrimPrefix(path, "/")
+			}
+
+			if strings.HasPrefix(path, word) {
+				kind := lspCompletionKindFile
+				if strings.HasSuffix(path, "/") {
+					kind = lspCompletionKindFolder
+				}
+
+				add(path, kind)
+			}
+		}
+	default:
+		for _, owner := range s.membership.Owners() {
+			if len(owner) >= len(word) && strings.EqualFold(owner[:len(word)], word) {
+				add(owner, lspCompletionKindValue)
+			}
+		}
+	}
+
+	return items
+}
+
+// definition goes from a section header to the first header with the same
+// name, which defines the approval count of the merged section.
+func (s *LanguageServer) definition(params lspTextDocumentPositionParams) any {
+	lines, file, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+
+	headers := file.sectionHeaderOccurrences()
+
+	for _, header := range headers {
+		if header.line != params.Position.Line+1 {
+			continue
+		}
+
+		for _, first := range headers {
+			if strings.EqualFold(first.section.name, header.section.name) {
+				return lspLocation{URI: params.TextDocument.URI, Range: lineRange(lines, first.line, 0)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// formatting replaces the document with the output of `File.Format`, a
+// document with parse errors is not formatted.
+func (s *LanguageServer) formatting(params lspDocumentFormattingParams) any {
+	edits := []lspTextEdit{}
+
+	lines, file, ok := s.document(params.TextDocument.URI)
+	if !ok || len(file.ParseErrors()) > 0 {
+		return edits
+	}
+
+	formatted := file.Format().String()
+	if formatted == s.documents[params.TextDocument.URI] {
+		return edits
+	}
+
+	last := len(lines) - 1
+	end := lspPosition{Line: last, Character: utf16Length(lines[last])}
+
+	return append(edits, lspTextEdit{Range: lspRange{Start: lspPosition{Line: 0, Character: 0}, End: end}, NewText: formatted})
+}
+
+// workspaceFiles returns the paths of all files in the workspace starting
+// with `/`, it returns nil if there is no workspace.
+func (s *LanguageServer) workspaceFiles() []string {
+	if s.files != nil || s.workspace == nil {
+		return s.files
+	}
+
+	files := []string{}
+
+	// unreadable directories are skipped, the other files are still useful
+	_ = fs.WalkDir(s.workspace, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if entry == nil || err != nil && !entry.IsDir() {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if err != nil || entry.Name() == ".git" {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		files = append(files, "/"+filePath)
+
+		return nil
+	})
+
+	s.files = files
+
+	return s.files
+}
+
+// workspacePaths returns the paths of all files and directories in the
+// workspace, the paths of directories end with `/`.
+func (s *LanguageServer) workspacePaths() []string {
+	seen := map[string]bool{}
+	paths := []string{}
+
+	for _, file := range s.workspaceFiles() {
+		paths = append(paths, file)
+
+		for dir := path.Dir(file); dir != "/" && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			paths = append(paths, dir+"/")
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// uriPath returns the path of a `file://` URI.
+func uriPath(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return "", false
+	}
+
+	filePath := parsed.Path
+
+	// remove the `/` before the drive letter of a Windows path
+	if len(filePath) > 2 && filePath[0] == '/' && filePath[2] == ':' {
+		filePath = filePath[1:]
+	}
+
+	return filepath.FromSlash(filePath), true
+}
+
+// splitLines splits a document into its lines without line breaks.
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines
+}
+
+// lineRange returns the range of the field starting at the column of the
+// line, both starting at 1. If the column is 0 the range covers the line
+// without the surrounding whitespace.
+func lineRange(lines []string, line, column int) lspRange {
+	if line < 1 || line > len(lines) {
+		return lspRange{Start: lspPosition{Line: 0, Character: 0}, End: lspPosition{Line: 0, Character: 0}}
+	}
+
+	text := lines[line-1]
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+	end := len(strings.TrimRight(text, " \t"))
+
+	if column > 0 {
+		start = min(runeByteOffset(text, column-1), len(text))
+
+		end = len(text)
+		if index := strings.IndexAny(text[start:], " \t"); index >= 0 {
+			end = start + index
+		}
+	}
+
+	return lspRange{
+		Start: lspPosition{Line: line - 1, Character: utf16Length(text[:start])},
+		End:   lspPosition{Line: line - 1, Character: utf16Length(text[:max(start, end)])},
+	}
+}
+
+// utf16Length returns the length of the text in UTF-16 code units, which
+// are used by the Language Server Protocol to count characters.
+func utf16Length(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// byteOffset returns the byte offset of a position given in UTF-16 code units.
+func byteOffset(text string, character int) int {
+	units := 0
+
+	for offset, char := range text {
+		if units >= character {
+			return offset
+		}
+
+		units += len(utf16.Encode([]rune{char}))
+	}
+
+	return len(text)
+}
+
+// runeByteOffset returns the byte offset of the rune with the given index.
+func runeByteOffset(text string, index int) int {
+	offset := 0
+
+	for i := 0; i < index && offset < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+
+	return offset
+}
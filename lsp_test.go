@@ -0,0 +1,276 @@
+package gitlabcodeowners
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const lspTestDocument = "* @org/all\n" +
+	"\n" +
+	"[Docs] docs-team\n" +
+	"docs/   @alice\n" +
+	"*.md @bob\n" +
+	"*.md @carol\n" +
+	"\n" +
+	"[docs][2] @bob\n" +
+	"/README.md\n"
+
+// lspSession sends the messages to a language server like a client and
+// returns the decoded messages written by the server.
+func lspSession(t *testing.T, server *LanguageServer, messages ...map[string]any) []map[string]any {
+	t.Helper()
+
+	input := bytes.Buffer{}
+
+	for _, message := range messages {
+		message["jsonrpc"] = "2.0"
+
+		if err := writeMessage(&input, message); err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+	}
+
+	output := bytes.Buffer{}
+	if err := server.Serve(&input, &output); err != nil {
+		t.Fatalf("Failed to serve: %v", err)
+	}
+
+	responses := []map[string]any{}
+	reader := bufio.NewReader(&output)
+
+	for reader.Buffered() > 0 || output.Len() > 0 {
+		content, err := readMessage(reader)
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+
+		var response map[string]any
+		if err := json.Unmarshal(content, &response); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+func lspRequest(id int, method string, params any) map[string]any {
+	return map[string]any{"id": id, "method": method, "params": params}
+}
+
+func lspPositionParams(line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": "file:///repo/CODEOWNERS"},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func lspTestRange(startLine, startCharacter, endLine, endCharacter float64) map[string]any {
+	return map[string]any{
+		"start": map[string]any{"line": startLine, "character": startCharacter},
+		"end":   map[string]any{"line": endLine, "character": endCharacter},
+	}
+}
+
+func TestLSP_Serve(t *testing.T) {
+	t.Parallel()
+
+	server := NewLanguageServer(
+		WithMembership(Membership{Users: []string{"@carol", "@Bert"}, Groups: map[string][]string{"@org/all": {"@alice", "@bob"}}}),
+		WithWorkspace(fstest.MapFS{
+			"README.md":       {Data: []byte{}},
+			"docs/index.md":   {Data: []byte{}},
+			"docs/api/ref.md": {Data: []byte{}},
+			"src/docs/x.go":   {Data: []byte{}},
+			".git/config":     {Data: []byte{}},
+		}),
+	)
+
+	got := lspSession(t, server,
+		lspRequest(1, "initialize", map[string]any{"rootUri": nil}),
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": "file:///repo/CODEOWNERS", "text": lspTestDocument},
+		}},
+		lspRequest(2, "textDocument/hover", lspPositionParams(3, 2)),
+		lspRequest(3, "textDocument/completion", lspPositionParams(3, 2)),
+		lspRequest(4, "textDocument/completion", lspPositionParams(4, 7)),
+		lspRequest(5, "textDocument/definition", lspPositionParams(7, 3)),
+		lspRequest(6, "textDocument/formatting", map[string]any{
+			"textDocument": map[string]any{"uri": "file:///repo/CODEOWNERS"},
+		}),
+		lspRequest(7, "textDocument/rename", map[string]any{}),
+		lspRequest(8, "shutdown", nil),
+		map[string]any{"method": "exit"},
+	)
+
+	diagnostic := func(r map[string]any, severity float64, code, message string) map[string]any {
+		return map[string]any{"range": r, "severity": severity, "code": code, "source": "gitlabcodeowners", "message": message}
+	}
+
+	completion := func(label string, kind float64, r map[string]any) map[string]any {
+		return map[string]any{"label": label, "kind": kind, "textEdit": map[string]any{"range": r, "newText": label}}
+	}
+
+	pathRange := lspTestRange(3, 0, 3, 2)
+	ownerRange := lspTestRange(4, 5, 4, 7)
+
+	testhelper.DeepEqual(t, got, []map[string]any{
+		{"jsonrpc": "2.0", "id": 1.0, "result": map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           1.0,
+				"hoverProvider":              true,
+				"completionProvider":         map[string]any{"triggerCharacters": []any{"@", "/"}},
+				"definitionProvider":         true,
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "gitlabcodeowners"},
+		}},
+		{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": map[string]any{
+			"uri": "file:///repo/CODEOWNERS",
+			"diagnostics": []any{
+				diagnostic(lspTestRange(2, 7, 2, 16), 1, "invalid-owner",
+					"owner 'docs-team' is neither prefixed with '@' nor a valid email address"),
+				diagnostic(lspTestRange(5, 0, 5, 11), 2, "duplicate-pattern",
+					"pattern '*.md' is already used on line 5, only the last rule applies"),
+				diagnostic(lspTestRange(7, 0, 7, 14), 2, "conflicting-duplicate-sections",
+					"section 'docs' (approvals: 2) is merged into the section on line 3 (approvals: 1), only the first header applies"),
+			},
+		}},
+		{"jsonrpc": "2.0", "id": 2.0, "result": map[string]any{
+			"contents": map[string]any{
+				"kind":  "markdown",
+				"value": "**Pattern** `docs/`\n\nNormalized glob: `/**/docs/**/*`\n\nMatches 3 of 4 files in the workspace.",
+			},
+			"range": lspTestRange(3, 0, 3, 5),
+		}},
+		{"jsonrpc": "2.0", "id": 3.0, "result": []any{
+			completion("docs/", 19, pathRange),
+			completion("docs/api/", 19, pathRange),
+			completion("docs/api/ref.md", 17, pathRange),
+			completion("docs/index.md", 17, pathRange),
+		}},
+		{"jsonrpc": "2.0", "id": 4.0, "result": []any{
+			completion("@Bert", 12, ownerRange),
+			completion("@bob", 12, ownerRange),
+		}},
+		{"jsonrpc": "2.0", "id": 5.0, "result": map[string]any{
+			"uri":   "file:///repo/CODEOWNERS",
+			"range": lspTestRange(2, 0, 2, 16),
+		}},
+		{"jsonrpc": "2.0", "id": 6.0, "result": []any{
+			map[string]any{
+				"range":   lspTestRange(0, 0, 9, 0),
+				"newText": "* @org/all\n\n[Docs] docs-team\ndocs/ @alice\n*.md @bob\n*.md @carol\n\n[docs][2] @bob\n/README.md\n",
+			},
+		}},
+		{"jsonrpc": "2.0", "id": 7.0, "error": map[string]any{
+			"code":    -32601.0,
+			"message": "method 'textDocument/rename' is not supported",
+		}},
+		{"jsonrpc": "2.0", "id": 8.0, "result": nil},
+	})
+}
+
+func TestLSP_Serve_parseFailure(t *testing.T) {
+	t.Parallel()
+
+	got := lspSession(t, NewLanguageServer(),
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": "file:///CODEOWNERS", "text": "* @a\n[Docs]]\n"},
+		}},
+		map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+			"textDocument":   map[string]any{"uri": "file:///CODEOWNERS"},
+			"contentChanges": []any{map[string]any{"text": "* @a\n"}},
+		}},
+		lspRequest(1, "textDocument/hover", map[string]any{
+			"textDocument": map[string]any{"uri": "file:///other"},
+			"position":     map[string]any{"line": 0, "character": 0},
+		}),
+	)
+
+	publish := func(diagnostics ...any) map[string]any {
+		return map[string]any{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": map[string]any{
+			"uri":         "file:///CODEOWNERS",
+			"diagnostics": append([]any{}, diagnostics...),
+		}}
+	}
+
+	testhelper.DeepEqual(t, got, []map[string]any{
+		publish(map[string]any{
+			"range":    lspTestRange(1, 6, 1, 7),
+			"severity": 1.0,
+			"code":     "parse-error",
+			"source":   "gitlabcodeowners",
+			"message":  "no matching bracket count",
+		}),
+		publish(),
+		{"jsonrpc": "2.0", "id": 1.0, "result": nil},
+	})
+}
+
+func TestLSP_readMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "message", input: "Content-Length: 2\r\n\r\n{}"},
+		{name: "missing length", input: "Content-Type: text\r\n\r\n{}", wantErr: errMissingContentLength},
+		{name: "negative length", input: "Content-Length: -1\r\n\r\n", wantErr: errMissingContentLength},
+		{name: "too large", input: fmt.Sprintf("Content-Length: %d\r\n\r\n", maxMessageLength+1), wantErr: errMessageTooLarge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readMessage(bufio.NewReader(strings.NewReader(tt.input)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, wanted %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLSP_lineRange(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"  *.md   @a ", "[Dökümentation] 🙂 @b"}
+
+	tests := []struct {
+		line   int
+		column int
+		want   lspRange
+	}{
+		{line: 0, column: 0, want: lspRange{}},
+		{line: 3, column: 1, want: lspRange{}},
+		{line: 1, column: 0, want: lspRange{Start: lspPosition{Line: 0, Character: 2}, End: lspPosition{Line: 0, Character: 11}}},
+		{line: 1, column: 10, want: lspRange{Start: lspPosition{Line: 0, Character: 9}, End: lspPosition{Line: 0, Character: 11}}},
+		{line: 2, column: 17, want: lspRange{Start: lspPosition{Line: 1, Character: 16}, End: lspPosition{Line: 1, Character: 18}}},
+		{line: 2, column: 19, want: lspRange{Start: lspPosition{Line: 1, Character: 19}, End: lspPosition{Line: 1, Character: 21}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(fmt.Sprintf("line %d column %d", tt.line, tt.column), func(t *testing.T) {
+			t.Parallel()
+
+			testhelper.DeepEqual(t, lineRange(lines, tt.line, tt.column), tt.want)
+		})
+	}
+}
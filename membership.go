@@ -0,0 +1,67 @@
+package gitlabcodeowners
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrInvalidMembership is returned if a membership snapshot can not be read.
+var ErrInvalidMembership = errors.New("invalid membership")
+
+// Membership is a snapshot of the users and groups of a Gitlab instance,
+// for example exported from its API. Snapshots are written in JSON:
+//
+//	{"users": ["@alice", "@bob"], "groups": {"@org/docs": ["@alice"]}}
+type Membership struct {
+	// Users contains the users, members of groups do not need to be listed.
+	Users []string `json:"users"`
+
+	// Groups maps each group to its members.
+	Groups map[string][]string `json:"groups"`
+}
+
+// ReadMembership reads a membership snapshot in the JSON format.
+func ReadMembership(reader io.Reader) (Membership, error) {
+	membership := Membership{Users: nil, Groups: nil}
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&membership); err != nil {
+		return Membership{}, fmt.Errorf("%w: %w", ErrInvalidMembership, err) //nolint:exhaustruct // not used on error
+	}
+
+	return membership, nil
+}
+
+// Owners returns all users, groups and members of groups sorted by name.
+func (m Membership) Owners() []string {
+	seen := map[string]bool{}
+	owners := []string{}
+
+	add := func(owner string) {
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+
+	for _, user := range m.Users {
+		add(user)
+	}
+
+	for group, members := range m.Groups {
+		add(group)
+
+		for _, member := range members {
+			add(member)
+		}
+	}
+
+	sort.Strings(owners)
+
+	return owners
+}
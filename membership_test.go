@@ -0,0 +1,26 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestMembership_ReadMembership(t *testing.T) {
+	t.Parallel()
+
+	membership, err := ReadMembership(strings.NewReader(
+		`{"users": ["@carol", "@alice"], "groups": {"@org/docs": ["@alice", "@bob"], "@org/all": ["@dave"]}}`,
+	))
+	if err != nil {
+		t.Fatalf("Failed to read membership: %v", err)
+	}
+
+	testhelper.DeepEqual(t, membership.Owners(), []string{"@alice", "@bob", "@carol", "@dave", "@org/all", "@org/docs"})
+
+	if _, err := ReadMembership(strings.NewReader(`{"members": []}`)); !errors.Is(err, ErrInvalidMembership) {
+		t.Errorf("got error %v for an unknown field, wanted %v", err, ErrInvalidMembership)
+	}
+}
@@ -0,0 +1,142 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+)
+
+// MigrationResult describes the changes made by `MigrateOwners`.
+type MigrationResult struct {
+	// Replaced is the number of owners which were replaced by another owner.
+	Replaced int
+
+	// Removed is the number of owners which were removed.
+	Removed int
+
+	// Warnings contains the rules which had owners before the migration,
+	// but have none afterwards and are therefore ignored by Gitlab.
+	Warnings []MigrationWarning
+}
+
+// MigrationWarning describes a rule which has no owners after a migration.
+type MigrationWarning struct {
+	Section string
+	Pattern string
+
+	// Line is the line number of the rule or 0 if it was not read from a file.
+	Line int
+}
+
+// MigrateOwners rewrites the owners of all section headers and rules
+// according to the given mapping from old to new owner. An owner which is
+// mapped to an empty string is removed and owners which are the same after
+// the migration are only kept once. Owners are compared case-insensitively.
+// The headers of duplicate sections are rewritten too, even though Gitlab
+// ignores their owners. Owners of aliases are migrated in the alias
+// directives, so lines using an alias keep it.
+func (f *File) MigrateOwners(mapping map[string]string) MigrationResult {
+	*f = f.clone()
+
+	normalized := make(map[string]string, len(mapping))
+	for oldOwner, newOwner := range mapping {
+		normalized[strings.ToLower(oldOwner)] = newOwner
+	}
+
+	result := MigrationResult{Replaced: 0, Removed: 0, Warnings: []MigrationWarning{}}
+
+	migrate := func(owners []string) ([]string, bool) {
+		migrated, replaced, removed := migrateOwners(owners, normalized)
+		result.Replaced += replaced
+		result.Removed += removed
+
+		return migrated, replaced+removed > 0
+	}
+
+	for i := range f.sections {
+		sec := &f.sections[i]
+		defaultOwnersBefore := sec.owners
+		rewriteOwnerLines(sec.src.leading, migrate)
+
+		if sec.aliasedOwners != nil {
+			sec.owners, _, _ = migrateOwners(sec.owners, normalized)
+		}
+
+		if owners, changed := migrate(writtenOwners(sec.owners, sec.aliasedOwners)); changed {
+			sec.owners, sec.aliasedOwners = rewrittenOwners(owners, sec.owners, sec.aliasedOwners)
+			sec.src.raw = ""
+		}
+
+		for j := range sec.rules {
+			r := &sec.rules[j]
+			rewriteOwnerLines(r.src.leading, migrate)
+			validBefore := isValidRule(*r, defaultOwnersBefore)
+
+			if r.aliasedOwners != nil {
+				r.owners, _, _ = migrateOwners(r.owners, normalized)
+			}
+
+			if owners, changed := migrate(writtenOwners(r.owners, r.aliasedOwners)); changed {
+				r.owners, r.aliasedOwners = rewrittenOwners(owners, r.owners, r.aliasedOwners)
+				r.selections = migrateSelections(r.selections, normalized)
+				r.src.raw = ""
+			}
+
+			if validBefore && !isValidRule(*r, sec.owners) {
+				result.Warnings = append(result.Warnings, MigrationWarning{
+					Section: sec.name,
+					Pattern: r.pattern.value,
+					Line:    r.src.line,
+				})
+			}
+		}
+	}
+
+	rewriteOwnerLines(f.trailing, migrate)
+
+	return result
+}
+
+func migrateOwners(owners []string, mapping map[string]string) (migrated []string, replaced, removed int) { //nolint:nonamedreturns,lll // give the return params a name
+	migrated = []string{}
+	seen := map[string]bool{}
+
+	add := func(owner string) {
+		if !seen[strings.ToLower(owner)] {
+			seen[strings.ToLower(owner)] = true
+			migrated = append(migrated, owner)
+		}
+	}
+
+	for _, owner := range owners {
+		newOwner, found := mapping[strings.ToLower(owner)]
+
+		switch {
+		case !found:
+			add(owner)
+		case newOwner == "":
+			removed++
+		default:
+			replaced++
+
+			add(newOwner)
+		}
+	}
+
+	return migrated, replaced, removed
+}
+
+// migrateSelections migrates the owners of the reviewer selections, which
+// are also part of the rule owners and therefore not counted again.
+func migrateSelections(selections []ReviewerSelection, mapping map[string]string) []ReviewerSelection {
+	var migrated []ReviewerSelection
+
+	for _, selection := range selections {
+		selection.Owners, _, _ = migrateOwners(selection.Owners, mapping)
+
+		// drop a selection without any owners left
+		if len(selection.Owners) > 0 {
+			migrated = append(migrated, selection)
+		}
+	}
+
+	return migrated
+}
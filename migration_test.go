@@ -0,0 +1,134 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestMigration_MigrateOwners(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		mapping map[string]string
+		want    string
+		result  MigrationResult
+	}{
+		{
+			name:    "nothing to migrate",
+			input:   "*.md @docs\n",
+			mapping: map[string]string{"@other": "@new"},
+			want:    "*.md @docs\n",
+			result:  MigrationResult{Replaced: 0, Removed: 0, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "rename owners in rules and headers",
+			input:   "* @Old\n\n[Docs] @old @docs\n*.md   @writers  @old\n",
+			mapping: map[string]string{"@old": "@new"},
+			want:    "* @new\n\n[Docs] @new @docs\n*.md @writers @new\n",
+			result:  MigrationResult{Replaced: 3, Removed: 0, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "rename owners in duplicate section headers",
+			input:   "[A] @old\na/\n\n[B]\nb/ @b\n\n[a] @old # ignored\nc/\n",
+			mapping: map[string]string{"@old": "@new"},
+			want:    "[A] @new\na/\n\n[B]\nb/ @b\n\n[a] @new # ignored\nc/\n",
+			result:  MigrationResult{Replaced: 2, Removed: 0, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "merge owners which are the same after the migration",
+			input:   "*.md @a @c\n",
+			mapping: map[string]string{"@a": "@C"},
+			want:    "*.md @C\n",
+			result:  MigrationResult{Replaced: 1, Removed: 0, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "migrate alias directives",
+			input:   "# alias @pay = @alice @bob\n/api/ @pay\n/web/ @alice\n",
+			mapping: map[string]string{"@alice": "@al"},
+			want:    "# alias @pay = @al @bob\n/api/ @pay\n/web/ @al\n",
+			result:  MigrationResult{Replaced: 2, Removed: 0, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "remove owners",
+			input:   "*.md @docs @old\n*.txt @keep\n",
+			mapping: map[string]string{"@old": ""},
+			want:    "*.md @docs\n*.txt @keep\n",
+			result:  MigrationResult{Replaced: 0, Removed: 1, Warnings: []MigrationWarning{}},
+		},
+		{
+			name:    "warn about rules without owners",
+			input:   "*.md @old\n\n[Docs] @old\ndocs/\nREADME.md @writers\n",
+			mapping: map[string]string{"@old": ""},
+			want:    "*.md\n\n[Docs]\ndocs/\nREADME.md @writers\n",
+			result: MigrationResult{
+				Replaced: 0,
+				Removed:  2,
+				Warnings: []MigrationWarning{
+					{Section: "", Pattern: "*.md", Line: 1},
+					{Section: "Docs", Pattern: "docs/", Line: 4},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			result := file.MigrateOwners(tt.mapping)
+			testhelper.DeepEqual(t, result, tt.result)
+
+			if got := file.String(); got != tt.want {
+				t.Errorf("got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigration_MigrateOwnersWithAliases(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("# alias @pay = @alice @bob\n/api/ @pay\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	file.MigrateOwners(map[string]string{"@alice": "@al"})
+
+	got := file.GetRequiredApprovalsForFile("/api/users.go")[""].Owners
+	testhelper.DeepEqual(t, got, []string{"@al", "@bob"})
+
+	reparsed, err := NewCodeOwnersFile(strings.NewReader(file.String()))
+	if err != nil {
+		t.Fatalf("Failed to parse migrated file: %v", err)
+	}
+
+	testhelper.DeepEqual(t, reparsed.GetRequiredApprovalsForFile("/api/users.go")[""].Owners, got)
+}
+
+func TestMigration_migrateSelections(t *testing.T) {
+	t.Parallel()
+
+	selections := []ReviewerSelection{
+		{Strategy: SelectionRandom, Count: 2, Owners: []string{"@@Old"}},
+		{Strategy: SelectionLeastBusy, Count: 1, Owners: []string{"@@Gone"}},
+	}
+
+	got := migrateSelections(selections, map[string]string{"@@old": "@@New", "@@gone": ""})
+	want := []ReviewerSelection{
+		{Strategy: SelectionRandom, Count: 2, Owners: []string{"@@New"}},
+	}
+
+	testhelper.DeepEqual(t, got, want)
+}
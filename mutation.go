@@ -0,0 +1,389 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSectionNotFound is returned if a section with the given name does not exist.
+	ErrSectionNotFound = errors.New("section not found")
+
+	// ErrSectionExists is returned if a section with the given name already exists.
+	ErrSectionExists = errors.New("section already exists")
+
+	// ErrRuleNotFound is returned if a rule with the given pattern does not exist.
+	ErrRuleNotFound = errors.New("rule not found")
+
+	// ErrInvalidApprovalCount is returned if an approval count is smaller than one.
+	ErrInvalidApprovalCount = errors.New("invalid approval count")
+
+	// ErrDefaultSection is returned if an operation needs a section header,
+	// which the default section without a name does not have.
+	ErrDefaultSection = errors.New("the default section has no header")
+)
+
+// AddSection adds a new required section with the given approval count and
+// default owners at the end of the file. Section names are compared
+// case-insensitively like Gitlab does when merging sections.
+// The section without a name is always added at the beginning of the file.
+func (f *File) AddSection(name string, approvals int, owners ...string) error {
+	*f = f.clone()
+
+	if approvals < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidApprovalCount, approvals)
+	}
+
+	if f.findSection(name) >= 0 {
+		return fmt.Errorf("%w: '%s'", ErrSectionExists, name)
+	}
+
+	sec := newSection(name, approvals, false, append([]string{}, owners...))
+
+	if name == "" {
+		f.sections = append([]section{sec}, f.sections...)
+
+		return nil
+	}
+
+	f.sections = append(f.sections, sec)
+
+	return nil
+}
+
+// AddRule adds a rule with the given pattern and owners at the end of the
+// section with the given name.
+func (f *File) AddRule(sectionName, pattern string, owners ...string) error {
+	*f = f.clone()
+
+	index := f.findSection(sectionName)
+	if index < 0 {
+		return fmt.Errorf("%w: '%s'", ErrSectionNotFound, sectionName)
+	}
+
+	f.sections[index].rules = append(
+		f.sections[index].rules,
+		newRule(newPattern(pattern), append([]string{}, owners...)),
+	)
+
+	return nil
+}
+
+// RemoveRule removes all rules with the given pattern from the section with
+// the given name. The comments directly above a removed rule are removed too.
+func (f *File) RemoveRule(sectionName, pattern string) error {
+	*f = f.clone()
+
+	index := f.findSection(sectionName)
+	if index < 0 {
+		return fmt.Errorf("%w: '%s'", ErrSectionNotFound, sectionName)
+	}
+
+	sec := &f.sections[index]
+	rules := []rule{}
+
+	var detached []string
+
+	for _, r := range sec.rules {
+		if r.pattern.value == pattern {
+			detached = append(detached, detachedLines(r.src.leading)...)
+
+			continue
+		}
+
+		// keep empty lines and comments which are not directly above
+		// the removed rule at the same place in the file
+		r.src.leading = append(detached, r.src.leading...)
+		detached = nil
+
+		rules = append(rules, r)
+	}
+
+	if len(rules) == len(sec.rules) {
+		return fmt.Errorf("%w: '%s' in section '%s'", ErrRuleNotFound, pattern, sectionName)
+	}
+
+	sec.rules = rules
+
+	if index+1 < len(f.sections) {
+		next := &f.sections[index+1]
+		next.src.leading = append(detached, next.src.leading...)
+	} else {
+		f.trailing = append(detached, f.trailing...)
+	}
+
+	return nil
+}
+
+// RemoveSection removes the section with the given name and all its rules.
+// The comments directly above the section header and the rules are removed too.
+func (f *File) RemoveSection(name string) error {
+	*f = f.clone()
+
+	index := f.findSection(name)
+	if index < 0 {
+		return fmt.Errorf("%w: '%s'", ErrSectionNotFound, name)
+	}
+
+	// keep empty lines and comments which are not directly above the
+	// removed section header at the same place in the file
+	detached := detachedLines(f.sections[index].src.leading)
+	f.sections = append(f.sections[:index], f.sections[index+1:]...)
+
+	if index < len(f.sections) {
+		next := &f.sections[index]
+		next.src.leading = append(withoutDoubleBlankLine(detached, next.src.leading), next.src.leading...)
+	} else {
+		f.trailing = append(withoutDoubleBlankLine(detached, f.trailing), f.trailing...)
+	}
+
+	return nil
+}
+
+// withoutDoubleBlankLine removes the empty line at the end of the lines if
+// the following lines start with an empty line too.
+func withoutDoubleBlankLine(lines, following []string) []string {
+	if len(lines) > 0 && len(following) > 0 &&
+		strings.TrimSpace(lines[len(lines)-1]) == "" && strings.TrimSpace(following[0]) == "" {
+		return lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// ReplaceOwner replaces the owner `oldOwner` with `newOwner` in all section
+// headers, rules and alias directives. Owners are compared
+// case-insensitively. It returns the number of replaced owners, where an
+// owner of an alias is only counted once in its alias directive.
+func (f *File) ReplaceOwner(oldOwner, newOwner string) int {
+	*f = f.clone()
+	replaced := 0
+
+	replace := func(owners []string) ([]string, bool) {
+		count := replaceOwner(owners, oldOwner, newOwner)
+		replaced += count
+
+		return owners, count > 0
+	}
+
+	for i := range f.sections {
+		sec := &f.sections[i]
+		rewriteOwnerLines(sec.src.leading, replace)
+
+		if sec.aliasedOwners != nil {
+			replaceOwner(sec.owners, oldOwner, newOwner)
+		}
+
+		if _, changed := replace(writtenOwners(sec.owners, sec.aliasedOwners)); changed {
+			sec.src.raw = ""
+		}
+
+		for j := range sec.rules {
+			r := &sec.rules[j]
+			rewriteOwnerLines(r.src.leading, replace)
+
+			if r.aliasedOwners != nil {
+				replaceOwner(r.owners, oldOwner, newOwner)
+			}
+
+			// the owners of the selections are part of the owners of the
+			// rule too, so they are not counted again
+			for _, selection := range r.selections {
+				replaceOwner(selection.Owners, oldOwner, newOwner)
+			}
+
+			if _, changed := replace(writtenOwners(r.owners, r.aliasedOwners)); changed {
+				r.src.raw = ""
+			}
+		}
+	}
+
+	rewriteOwnerLines(f.trailing, replace)
+
+	return replaced
+}
+
+// writtenOwners returns the owners as they are written in the file.
+func writtenOwners(owners, aliasedOwners []string) []string {
+	if aliasedOwners != nil {
+		return aliasedOwners
+	}
+
+	return owners
+}
+
+// rewrittenOwners returns the owners and the owners written with aliases
+// after the written owners were rewritten.
+func rewrittenOwners(written, owners, aliasedOwners []string) ([]string, []string) {
+	if aliasedOwners != nil {
+		return owners, written
+	}
+
+	return written, nil
+}
+
+// rewriteOwnerLines changes the owners of the alias directives and section
+// headers between the comments and empty lines, like the headers of merged
+// duplicate sections or of sections without rules.
+func rewriteOwnerLines(lines []string, rewrite func(owners []string) ([]string, bool)) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if directive, ok := rewriteAliasDirective(trimmed, rewrite); ok {
+			lines[i] = directive
+
+			continue
+		}
+
+		if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "^[") {
+			continue
+		}
+
+		sec, err := parseSectionHeaderLine(trimmed)
+		if err != nil {
+			continue
+		}
+
+		if owners, changed := rewrite(sec.owners); changed {
+			sec.owners = owners
+			lines[i] = renderSectionHeader(sec)
+		}
+	}
+}
+
+// SetApprovals changes the approval count of the section with the given
+// name. Setting the approval count of an optional section makes it required.
+// The default section without a name has no header to store the count in.
+func (f *File) SetApprovals(sectionName string, approvals int) error {
+	if approvals < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidApprovalCount, approvals)
+	}
+
+	index, err := f.findNamedSection(sectionName)
+	if err != nil {
+		return err
+	}
+
+	*f = f.clone()
+
+	sec := &f.sections[index]
+	sec.approvals = approvals
+	sec.optional = false
+	sec.src.raw = ""
+
+	return nil
+}
+
+// SetOptional marks the section with the given name as optional or as
+// required. An optional section requires no approvals and a section which
+// becomes required again requires one approval. The default section without
+// a name can not be optional.
+func (f *File) SetOptional(sectionName string, optional bool) error {
+	index, err := f.findNamedSection(sectionName)
+	if err != nil {
+		return err
+	}
+
+	if f.sections[index].optional == optional {
+		return nil
+	}
+
+	*f = f.clone()
+	sec := &f.sections[index]
+
+	sec.optional = optional
+	sec.approvals = 0
+	sec.src.raw = ""
+
+	if !optional {
+		sec.approvals = 1
+	}
+
+	return nil
+}
+
+func (f *File) findSection(name string) int {
+	for i, sec := range f.sections {
+		if strings.EqualFold(sec.name, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// findNamedSection returns the index of the section with the given name, it
+// fails for the default section, because it has no header.
+func (f *File) findNamedSection(name string) (int, error) {
+	if name == "" {
+		return -1, ErrDefaultSection
+	}
+
+	index := f.findSection(name)
+	if index < 0 {
+		return -1, fmt.Errorf("%w: '%s'", ErrSectionNotFound, name)
+	}
+
+	return index, nil
+}
+
+func replaceOwner(owners []string, oldOwner, newOwner string) int {
+	count := 0
+
+	for i, owner := range owners {
+		if strings.EqualFold(owner, oldOwner) {
+			owners[i] = newOwner
+			count++
+		}
+	}
+
+	return count
+}
+
+// detachedLines returns the leading lines without the comments directly
+// above the line, which are considered to belong to the line itself.
+func detachedLines(leading []string) []string {
+	return append([]string(nil), leading[:commentBlockStart(leading)]...)
+}
+
+// clone returns a copy of the file which can be modified without changing
+// the original file.
+func (f File) clone() File {
+	cloned := f
+	cloned.sections = make([]section, len(f.sections))
+	cloned.trailing = append([]string(nil), f.trailing...)
+
+	for i, sec := range f.sections {
+		sec.owners = append([]string{}, sec.owners...)
+		sec.aliasedOwners = cloneOwners(sec.aliasedOwners)
+		sec.src.leading = append([]string(nil), sec.src.leading...)
+		sec.rules = append([]rule{}, sec.rules...)
+
+		for j, r := range sec.rules {
+			r.owners = append([]string{}, r.owners...)
+			r.aliasedOwners = cloneOwners(r.aliasedOwners)
+			r.src.leading = append([]string(nil), r.src.leading...)
+			r.selections = append([]ReviewerSelection(nil), r.selections...)
+
+			for k := range r.selections {
+				r.selections[k].Owners = append([]string{}, r.selections[k].Owners...)
+			}
+
+			sec.rules[j] = r
+		}
+
+		cloned.sections[i] = sec
+	}
+
+	return cloned
+}
+
+// cloneOwners returns a copy of the owners, which keeps nil owners nil.
+func cloneOwners(owners []string) []string {
+	if owners == nil {
+		return nil
+	}
+
+	return append([]string{}, owners...)
+}
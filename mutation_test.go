@@ -0,0 +1,302 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const mutationInput = `# Required for all files
+* @general-approvers
+
+[Documentation][2] @docs-team
+docs/
+# the readme is special
+README.md @writers
+
+[Database] @database-team
+model/db/ @dba
+`
+
+func TestMutation_operations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mutate  func(f *File) error
+		want    string
+		wantErr error
+	}{
+		{
+			name: "add rule",
+			mutate: func(f *File) error {
+				return f.AddRule("database", "migrations/", "@dba", "@alice")
+			},
+			want:    mutationInput + "migrations/ @dba @alice\n",
+			wantErr: nil,
+		},
+		{
+			name: "add rule to missing section",
+			mutate: func(f *File) error {
+				return f.AddRule("Frontend", "web/")
+			},
+			want:    mutationInput,
+			wantErr: ErrSectionNotFound,
+		},
+		{
+			name: "add section",
+			mutate: func(f *File) error {
+				if err := f.AddSection("Frontend", 1, "@web"); err != nil {
+					return err
+				}
+
+				return f.AddRule("Frontend", "web/")
+			},
+			want:    mutationInput + "\n[Frontend] @web\nweb/\n",
+			wantErr: nil,
+		},
+		{
+			name: "add existing section",
+			mutate: func(f *File) error {
+				return f.AddSection("DATABASE", 1)
+			},
+			want:    mutationInput,
+			wantErr: ErrSectionExists,
+		},
+		{
+			name: "remove rule with its comment",
+			mutate: func(f *File) error {
+				return f.RemoveRule("Documentation", "README.md")
+			},
+			want:    strings.Replace(mutationInput, "# the readme is special\nREADME.md @writers\n", "", 1),
+			wantErr: nil,
+		},
+		{
+			name: "remove last rule of a section",
+			mutate: func(f *File) error {
+				return f.RemoveRule("Database", "model/db/")
+			},
+			want:    strings.Replace(mutationInput, "model/db/ @dba\n", "", 1),
+			wantErr: nil,
+		},
+		{
+			name: "remove missing rule",
+			mutate: func(f *File) error {
+				return f.RemoveRule("Database", "*.sql")
+			},
+			want:    mutationInput,
+			wantErr: ErrRuleNotFound,
+		},
+		{
+			name: "remove section",
+			mutate: func(f *File) error {
+				return f.RemoveSection("documentation")
+			},
+			want:    strings.Replace(mutationInput, "[Documentation][2] @docs-team\ndocs/\n# the readme is special\nREADME.md @writers\n\n", "", 1),
+			wantErr: nil,
+		},
+		{
+			name: "remove missing section",
+			mutate: func(f *File) error {
+				return f.RemoveSection("Frontend")
+			},
+			want:    mutationInput,
+			wantErr: ErrSectionNotFound,
+		},
+		{
+			name: "replace owner",
+			mutate: func(f *File) error {
+				if count := f.ReplaceOwner("@DOCS-TEAM", "@documentation"); count != 1 {
+					return errors.New("unexpected replace count") //nolint:goerr113 // only used in test
+				}
+
+				return nil
+			},
+			want:    strings.Replace(mutationInput, "[Documentation][2] @docs-team", "[Documentation][2] @documentation", 1),
+			wantErr: nil,
+		},
+		{
+			name: "set approvals",
+			mutate: func(f *File) error {
+				return f.SetApprovals("Database", 3)
+			},
+			want:    strings.Replace(mutationInput, "[Database]", "[Database][3]", 1),
+			wantErr: nil,
+		},
+		{
+			name: "set invalid approvals",
+			mutate: func(f *File) error {
+				return f.SetApprovals("Database", 0)
+			},
+			want:    mutationInput,
+			wantErr: ErrInvalidApprovalCount,
+		},
+		{
+			name: "set approvals of the default section",
+			mutate: func(f *File) error {
+				return f.SetApprovals("", 2)
+			},
+			want:    mutationInput,
+			wantErr: ErrDefaultSection,
+		},
+		{
+			name: "set default section optional",
+			mutate: func(f *File) error {
+				return f.SetOptional("", true)
+			},
+			want:    mutationInput,
+			wantErr: ErrDefaultSection,
+		},
+		{
+			name: "set optional",
+			mutate: func(f *File) error {
+				return f.SetOptional("Documentation", true)
+			},
+			want:    strings.Replace(mutationInput, "[Documentation][2]", "^[Documentation]", 1),
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(mutationInput))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			err = tt.mutate(&file)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, wanted %v", err, tt.wantErr)
+			}
+
+			if got := file.String(); got != tt.want {
+				t.Errorf("got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMutation_SetOptional(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("^[Docs] @docs\n*.md\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	if err := file.SetOptional("Docs", false); err != nil {
+		t.Fatalf("Failed to mark section as required: %v", err)
+	}
+
+	if got := file.GetRequiredApprovalsForFile("/README.md")["Docs"].Approvals; got != 1 {
+		t.Errorf("got %d approvals, wanted 1", got)
+	}
+
+	if got, want := file.String(), "[Docs] @docs\n*.md\n"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestMutation_copiesAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(mutationInput))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	changed := file
+	changed.ReplaceOwner("@dba", "@alice")
+
+	if err := changed.SetApprovals("Database", 2); err != nil {
+		t.Fatalf("Failed to set approvals: %v", err)
+	}
+
+	if err := changed.AddRule("Database", "*.sql"); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	if got := file.String(); got != mutationInput {
+		t.Errorf("got %q, wanted %q", got, mutationInput)
+	}
+}
+
+func TestMutation_ReplaceOwnerInAlias(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("# alias @pay = @alice @bob\n/api/ @pay\n[Web] @pay\n/web/\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	if got := file.ReplaceOwner("@alice", "@al"); got != 1 {
+		t.Errorf("got %d replaced owners, wanted 1", got)
+	}
+
+	if got, want := file.String(), "# alias @pay = @al @bob\n/api/ @pay\n[Web] @pay\n/web/\n"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	testhelper.DeepEqual(t, file.GetRequiredApprovalsForFile("/api/users.go")[""].Owners, []string{"@al", "@bob"})
+	testhelper.DeepEqual(t, file.GetRequiredApprovalsForFile("/web/index.js")["Web"].Owners, []string{"@al", "@bob"})
+}
+
+func TestMutation_ReplaceOwnerInSelection(t *testing.T) {
+	t.Parallel()
+
+	input := "* Random(@alice, @bob, 1)\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(input), WithDialect(DialectBitbucket))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	if got := file.ReplaceOwner("@alice", "@carol"); got != 1 {
+		t.Errorf("got %d replaced owners, wanted 1", got)
+	}
+
+	if got, want := file.String(), "* Random(@carol, @bob, 1)\n"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestMutation_detachedLines(t *testing.T) {
+	t.Parallel()
+
+	got := detachedLines([]string{"", "[DOCS]", "# separate", "", "# attached", "# comment"})
+	want := []string{"", "[DOCS]", "# separate", ""}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestMutation_duplicateSections(t *testing.T) {
+	t.Parallel()
+
+	input := "[Docs]\ndocs/ @docs\n\n[Database]\nmodel/ @dba\n\n[DOCS]\nREADME.md @docs\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	if err := file.AddRule("Docs", "*.md", "@writers"); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	if err := file.AddRule("Database", "*.sql"); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	want := "[Docs]\ndocs/ @docs\n\n[Database]\nmodel/ @dba\n*.sql\n\n[DOCS]\nREADME.md @docs\n*.md @writers\n"
+	if got := file.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
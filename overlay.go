@@ -0,0 +1,461 @@
+package gitlabcodeowners
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dropDirectiveRegex matches a comment like `# drop [Documentation]` which
+// removes a section from the base file.
+var dropDirectiveRegex = regexp.MustCompile(`^#\s*drop\s+\[([^\[\]]+)\]\s*$`)
+
+// OverlayAction describes how an overlay changed the base file.
+type OverlayAction string
+
+const (
+	// OverlaySectionAdded is reported for a section which is not in the base file.
+	OverlaySectionAdded OverlayAction = "section-added"
+
+	// OverlaySectionDropped is reported for a section removed by a `# drop [Name]` directive.
+	OverlaySectionDropped OverlayAction = "section-dropped"
+
+	// OverlayOwnersReplaced is reported if the default owners of a section were replaced.
+	OverlayOwnersReplaced OverlayAction = "owners-replaced"
+
+	// OverlayApprovalsChanged is reported if the approval count of a section
+	// was changed or if it was made optional.
+	OverlayApprovalsChanged OverlayAction = "approvals-changed"
+
+	// OverlayRuleReplaced is reported for a rule which replaced the rules with
+	// the same pattern in the section.
+	OverlayRuleReplaced OverlayAction = "rule-replaced"
+
+	// OverlayRuleAdded is reported for a rule whose pattern is not in the section.
+	OverlayRuleAdded OverlayAction = "rule-added"
+)
+
+// OverlayChange describes a change of the base file made by an overlay.
+type OverlayChange struct {
+	Action OverlayAction
+
+	// Section is the name of the changed section.
+	Section string
+
+	// Pattern is the pattern of the changed rule, it is empty if the
+	// section itself was changed.
+	Pattern string
+
+	// Before and After describe the overridden value and the new value,
+	// for example the owners or the approval count.
+	Before string
+	After  string
+
+	// Line is the line in the overlay which caused the change.
+	Line int
+}
+
+// Overlay contains the changes which are layered on top of a base file, for
+// example to use other owners in a fork without editing the upstream file.
+type Overlay struct {
+	sections []overlaySection
+	drops    []overlayDrop
+
+	// aliases contains the alias directives of the overlay.
+	aliases []string
+}
+
+type overlaySection struct {
+	section section
+
+	// approvalCount is set if the header contains an approval count.
+	approvalCount bool
+}
+
+type overlayDrop struct {
+	name string
+	line int
+}
+
+// NewOverlay parses an overlay written in the Gitlab syntax. Unlike in a
+// `CODEOWNERS` file, a section header without rules is kept to override the
+// default owners or the approval count of a section. A section of the base
+// file is removed with a `# drop [Name]` directive.
+func NewOverlay(reader io.Reader) (Overlay, error) {
+	file, err := parseFile(reader, parseConfig{dialect: DialectGitLab, strict: true, maxFileSize: 0})
+	if err != nil {
+		return Overlay{}, err //nolint:exhaustruct // not used on error
+	}
+
+	builder := overlayBuilder{
+		overlay: Overlay{sections: []overlaySection{}, drops: []overlayDrop{}, aliases: []string{}},
+		current: overlaySection{section: newSection("", 1, false, []string{}), approvalCount: false},
+		aliases: aliases{},
+		pending: nil,
+	}
+
+	next := 1
+
+	for _, item := range file.overlayItems() {
+		builder.addLines(item.leading, item.line-len(item.leading))
+		builder.addItem(item)
+
+		next = item.line + 1
+	}
+
+	builder.addLines(file.trailing, next)
+	builder.overlay.sections = append(builder.overlay.sections, builder.current)
+
+	return builder.overlay, nil
+}
+
+// overlayItem is a section header or a rule of a parsed overlay.
+type overlayItem struct {
+	line    int
+	leading []string
+	header  *section
+	rule    *rule
+}
+
+// overlayItems returns the section headers and rules in the order of the
+// file. The parser merges sections with duplicate names and keeps headers of
+// sections without rules as leading lines, which an overlay has to keep as
+// separate sections, so they are read again from the leading lines.
+func (f File) overlayItems() []overlayItem {
+	items := []overlayItem{}
+
+	for i := range f.sections {
+		sec := &f.sections[i]
+		if sec.src.line > 0 {
+			items = append(items, overlayItem{line: sec.src.line, leading: sec.src.leading, header: sec, rule: nil})
+		}
+
+		for j := range sec.rules {
+			r := &sec.rules[j]
+			items = append(items, overlayItem{line: r.src.line, leading: r.src.leading, header: nil, rule: r})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].line < items[j].line
+	})
+
+	return items
+}
+
+// overlayBuilder builds an overlay from the lines of a parsed file.
+type overlayBuilder struct {
+	overlay Overlay
+	current overlaySection
+	aliases aliases
+	pending []string
+}
+
+// addLines adds the comments, empty lines and headers of sections without
+// rules, where `first` is the line number of the first line. The lines were
+// already checked by the parser, so they can not contain errors.
+func (b *overlayBuilder) addLines(lines []string, first int) {
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		if match := dropDirectiveRegex.FindStringSubmatch(line); match != nil {
+			b.overlay.drops = append(b.overlay.drops, overlayDrop{name: strings.TrimSpace(match[1]), line: first + i})
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			sec, _ := parseSectionHeaderLine(line)
+			sec.owners, sec.aliasedOwners = b.aliases.expand(sec.owners)
+			b.startSection(sec, line, first+i)
+
+			continue
+		}
+
+		if name, owners, ok, _ := b.aliases.parseAliasDirective(line); ok {
+			b.aliases[name] = owners
+			b.overlay.aliases = append(b.overlay.aliases, line)
+		} else {
+			b.pending = append(b.pending, raw)
+		}
+	}
+}
+
+// addItem adds a section header or a rule read by the parser.
+func (b *overlayBuilder) addItem(item overlayItem) {
+	if item.header != nil {
+		b.startSection(*item.header, strings.TrimSpace(item.header.src.raw), item.line)
+
+		return
+	}
+
+	added := *item.rule
+	added.src = source{line: item.line, raw: "", leading: commentBlock(b.pending)}
+	b.pending = nil
+
+	b.current.section.rules = append(b.current.section.rules, added)
+}
+
+func (b *overlayBuilder) startSection(sec section, header string, line int) {
+	sec.rules = []rule{}
+	sec.src = source{line: line, raw: "", leading: commentBlock(b.pending)}
+	b.pending = nil
+
+	b.overlay.sections = append(b.overlay.sections, b.current)
+	b.current = overlaySection{section: sec, approvalCount: hasApprovalCount(header[:sectionHeaderEnd(header)])}
+}
+
+// ApplyOverlay returns a copy of the file with the overlay layered on top of
+// it and a report of all changes. Sections are dropped first, so a dropped
+// section can be defined again by the overlay. Lines of the file which are
+// not changed by the overlay keep their formatting. The alias directives of
+// the overlay are added at the beginning of the file, unless the file
+// defines an alias with the same name, in which case the owners using the
+// alias are written expanded.
+func (f File) ApplyOverlay(overlay Overlay) (File, []OverlayChange) {
+	merged := f.clone()
+	changes := []OverlayChange{}
+	overlay = overlay.withAliasesOf(f)
+
+	for _, drop := range overlay.drops {
+		index := merged.findSection(drop.name)
+		if index < 0 {
+			continue
+		}
+
+		name := merged.sections[index].name
+		_ = merged.RemoveSection(name)
+
+		changes = append(changes, newOverlayChange(OverlaySectionDropped, name, "", "", "", drop.line))
+	}
+
+	for _, sec := range overlay.sections {
+		if sec.section.name == "" && len(sec.section.rules) == 0 {
+			continue
+		}
+
+		changes = merged.applyOverlaySection(sec, changes)
+	}
+
+	merged.addAliasDirectives(overlay.aliases)
+
+	return merged, changes
+}
+
+// withAliasesOf returns the overlay without the aliases which are defined by
+// the file too, the owners using such an alias are expanded.
+func (o Overlay) withAliasesOf(f File) Overlay {
+	defined := f.aliasNames()
+	conflicting := map[string]bool{}
+	kept := []string{}
+
+	for _, directive := range o.aliases {
+		name := aliasDirectiveRegex.FindStringSubmatch(directive)[1]
+		if defined[name] {
+			conflicting[name] = true
+		} else {
+			kept = append(kept, directive)
+		}
+	}
+
+	if len(conflicting) == 0 {
+		return o
+	}
+
+	expand := func(aliasedOwners []string) []string {
+		for _, owner := range aliasedOwners {
+			if conflicting[owner] {
+				return nil
+			}
+		}
+
+		return aliasedOwners
+	}
+
+	sections := make([]overlaySection, 0, len(o.sections))
+
+	for _, sec := range o.sections {
+		sec.section.aliasedOwners = expand(sec.section.aliasedOwners)
+		sec.section.rules = append([]rule{}, sec.section.rules...)
+
+		for i := range sec.section.rules {
+			sec.section.rules[i].aliasedOwners = expand(sec.section.rules[i].aliasedOwners)
+		}
+
+		sections = append(sections, sec)
+	}
+
+	return Overlay{sections: sections, drops: o.drops, aliases: kept}
+}
+
+// aliasNames returns the names of all aliases defined in the file.
+func (f File) aliasNames() map[string]bool {
+	names := map[string]bool{}
+
+	add := func(lines []string) {
+		for _, line := range lines {
+			if match := aliasDirectiveRegex.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+				names[match[1]] = true
+			}
+		}
+	}
+
+	for _, sec := range f.sections {
+		add(sec.src.leading)
+
+		for _, r := range sec.rules {
+			add(r.src.leading)
+		}
+	}
+
+	add(f.trailing)
+
+	return names
+}
+
+// addAliasDirectives adds the alias directives in front of the first line.
+func (f *File) addAliasDirectives(directives []string) {
+	if len(directives) == 0 {
+		return
+	}
+
+	if len(f.sections) == 0 {
+		f.trailing = append(append([]string{}, directives...), f.trailing...)
+
+		return
+	}
+
+	first := &f.sections[0].src
+	first.leading = append(append([]string{}, directives...), first.leading...)
+}
+
+func (f *File) applyOverlaySection(overlay overlaySection, changes []OverlayChange) []OverlayChange {
+	sec := overlay.section
+	line := sec.src.line
+
+	index := f.findSection(sec.name)
+	if index < 0 {
+		added := sec
+		added.rules = []rule{}
+		added.src = source{line: 0, raw: "", leading: sec.src.leading}
+
+		if sec.name == "" {
+			f.sections = append([]section{added}, f.sections...)
+			index = 0
+		} else {
+			f.sections = append(f.sections, added)
+			index = len(f.sections) - 1
+		}
+
+		changes = append(changes, newOverlayChange(OverlaySectionAdded, sec.name, "", "", renderSectionHeader(sec), line))
+	} else {
+		changes = f.sections[index].applyOverlayHeader(overlay, changes)
+	}
+
+	for _, r := range sec.rules {
+		changes = f.sections[index].applyOverlayRule(r, changes)
+	}
+
+	return changes
+}
+
+func (s *section) applyOverlayHeader(overlay overlaySection, changes []OverlayChange) []OverlayChange {
+	sec := overlay.section
+	line := sec.src.line
+
+	if len(sec.owners) > 0 && !slices.Equal(s.owners, sec.owners) {
+		changes = append(changes, newOverlayChange(
+			OverlayOwnersReplaced, s.name, "", strings.Join(s.owners, " "), strings.Join(sec.owners, " "), line,
+		))
+
+		s.owners = append([]string{}, sec.owners...)
+		s.aliasedOwners = cloneOwners(sec.aliasedOwners)
+		s.src.raw = ""
+	}
+
+	if (sec.optional || overlay.approvalCount) && (s.optional != sec.optional || s.approvals != sec.approvals) {
+		changes = append(changes, newOverlayChange(
+			OverlayApprovalsChanged, s.name, "", approvalsText(*s), approvalsText(sec), line,
+		))
+
+		s.optional = sec.optional
+		s.approvals = sec.approvals
+		s.src.raw = ""
+	}
+
+	if sec.comment != "" && s.comment != sec.comment {
+		s.comment = sec.comment
+		s.src.raw = ""
+	}
+
+	return changes
+}
+
+func (s *section) applyOverlayRule(overlay rule, changes []OverlayChange) []OverlayChange {
+	line := overlay.src.line
+	overlay.src = source{line: 0, raw: "", leading: overlay.src.leading}
+
+	replaced := []string{}
+
+	for i := range s.rules {
+		r := &s.rules[i]
+		if r.pattern.value != overlay.pattern.value {
+			continue
+		}
+
+		replaced = append(replaced, renderRule(*r))
+
+		r.owners = append([]string{}, overlay.owners...)
+		r.selections = overlay.selections
+		r.aliasedOwners = cloneOwners(overlay.aliasedOwners)
+		r.src.raw = ""
+
+		if overlay.comment != "" {
+			r.comment = overlay.comment
+		}
+	}
+
+	if len(replaced) > 0 {
+		return append(changes, newOverlayChange(
+			OverlayRuleReplaced, s.name, overlay.pattern.value, strings.Join(replaced, "\n"), renderRule(overlay), line,
+		))
+	}
+
+	s.rules = append(s.rules, overlay)
+
+	return append(changes, newOverlayChange(OverlayRuleAdded, s.name, overlay.pattern.value, "", renderRule(overlay), line))
+}
+
+func newOverlayChange(action OverlayAction, section, pattern, before, after string, line int) OverlayChange {
+	return OverlayChange{
+		Action:  action,
+		Section: section,
+		Pattern: pattern,
+		Before:  before,
+		After:   after,
+		Line:    line,
+	}
+}
+
+// hasApprovalCount reports if the section header contains an approval count.
+func hasApprovalCount(header string) bool {
+	_, approvals, _, err := extractPartsFromSectionHeader(strings.TrimPrefix(header, "^"))
+
+	return err == nil && strings.TrimSpace(approvals) != ""
+}
+
+func approvalsText(sec section) string {
+	if sec.optional {
+		return "optional"
+	}
+
+	return strconv.Itoa(sec.approvals)
+}
+
+// commentBlock returns the comments directly above a line.
+func commentBlock(leading []string) []string {
+	return append([]string(nil), leading[commentBlockStart(leading):]...)
+}
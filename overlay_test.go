@@ -0,0 +1,181 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const overlayBase = `* @upstream-maintainers
+
+[Documentation][2] @docs-team
+docs/
+README.md @writers
+
+^[Translations] @i18n
+locales/
+
+[Database] @database-team
+model/db/ @dba
+`
+
+func TestOverlay_ApplyOverlay(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewCodeOwnersFile(strings.NewReader(overlayBase))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	overlay, err := NewOverlay(strings.NewReader(`# alias @fork = @alice @bob
+* @fork
+
+# drop [translations]
+
+[documentation][1] @fork-docs
+
+^[Database]
+model/db/ @fork-dba # internal DBA team
+# only in the fork
+deploy/ @ops
+
+[Security] @security
+*.pem
+`))
+	if err != nil {
+		t.Fatalf("Failed to create overlay: %v", err)
+	}
+
+	merged, changes := base.ApplyOverlay(overlay)
+
+	want := `# alias @fork = @alice @bob
+* @fork
+
+[Documentation] @fork-docs
+docs/
+README.md @writers
+
+^[Database] @database-team
+model/db/ @fork-dba # internal DBA team
+# only in the fork
+deploy/ @ops
+
+[Security] @security
+*.pem
+`
+	if got := merged.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if got := base.String(); got != overlayBase {
+		t.Errorf("base file was modified: %q", got)
+	}
+
+	testhelper.DeepEqual(t, changes, []OverlayChange{
+		{Action: OverlaySectionDropped, Section: "Translations", Line: 4},
+		{Action: OverlayRuleReplaced, Pattern: "*", Before: "* @upstream-maintainers", After: "* @fork", Line: 2},
+		{Action: OverlayOwnersReplaced, Section: "Documentation", Before: "@docs-team", After: "@fork-docs", Line: 6},
+		{Action: OverlayApprovalsChanged, Section: "Documentation", Before: "2", After: "1", Line: 6},
+		{Action: OverlayApprovalsChanged, Section: "Database", Before: "1", After: "optional", Line: 8},
+		{
+			Action:  OverlayRuleReplaced,
+			Section: "Database",
+			Pattern: "model/db/",
+			Before:  "model/db/ @dba",
+			After:   "model/db/ @fork-dba # internal DBA team",
+			Line:    9,
+		},
+		{Action: OverlayRuleAdded, Section: "Database", Pattern: "deploy/", After: "deploy/ @ops", Line: 11},
+		{Action: OverlaySectionAdded, Section: "Security", After: "[Security] @security", Line: 13},
+		{Action: OverlayRuleAdded, Section: "Security", Pattern: "*.pem", After: "*.pem", Line: 14},
+	})
+}
+
+func TestOverlay_redefineDroppedSection(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewCodeOwnersFile(strings.NewReader(overlayBase))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	overlay, err := NewOverlay(strings.NewReader("# drop [Database]\n[Database] @fork-dba\nmigrations/\n"))
+	if err != nil {
+		t.Fatalf("Failed to create overlay: %v", err)
+	}
+
+	merged, _ := base.ApplyOverlay(overlay)
+
+	approvals := merged.GetRequiredApprovalsForFile("/model/db/schema.sql")
+	if _, ok := approvals["Database"]; ok {
+		t.Errorf("expected the rules of the dropped section to be removed, got %v", approvals)
+	}
+
+	approvals = merged.GetRequiredApprovalsForFile("/migrations/001.sql")
+	testhelper.DeepEqual(t, approvals["Database"].Owners, []string{"@fork-dba"})
+}
+
+func TestOverlay_aliases(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewCodeOwnersFile(strings.NewReader("# alias @team = @a @b\n* @team\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	overlay, err := NewOverlay(strings.NewReader("# alias @team = @x\n# alias @ops = @y\n/api/ @team\n[Ops]\n/ops/ @ops\n"))
+	if err != nil {
+		t.Fatalf("Failed to create overlay: %v", err)
+	}
+
+	merged, _ := base.ApplyOverlay(overlay)
+
+	// the alias of the base wins, the overlay rule using the same name is expanded
+	want := "# alias @ops = @y\n# alias @team = @a @b\n* @team\n/api/ @x\n\n[Ops]\n/ops/ @ops\n"
+	if got := merged.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	testhelper.DeepEqual(t, merged.GetRequiredApprovalsForFile("/ops/run.sh")["Ops"].Owners, []string{"@y"})
+}
+
+func TestOverlay_sectionsWithoutRules(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewCodeOwnersFile(strings.NewReader("[Docs] @a\ndocs/\n[Ops] @o\nops/\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	overlay, err := NewOverlay(strings.NewReader("[Docs]\n*.md @m\n\n# drop [Ops]\n[Docs][2]\n# later\n[Security] @sec\n"))
+	if err != nil {
+		t.Fatalf("Failed to create overlay: %v", err)
+	}
+
+	merged, changes := base.ApplyOverlay(overlay)
+
+	want := "[Docs][2] @a\ndocs/\n*.md @m\n\n# later\n[Security] @sec\n"
+	if got := merged.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	testhelper.DeepEqual(t, changes, []OverlayChange{
+		{Action: OverlaySectionDropped, Section: "Ops", Line: 4},
+		{Action: OverlayRuleAdded, Section: "Docs", Pattern: "*.md", After: "*.md @m", Line: 2},
+		{Action: OverlayApprovalsChanged, Section: "Docs", Before: "1", After: "2", Line: 5},
+		{Action: OverlaySectionAdded, Section: "Security", After: "[Security] @sec", Line: 7},
+	})
+}
+
+func TestOverlay_NewOverlay(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewOverlay(strings.NewReader("* @a\n[Docs\n"))
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || !errors.Is(err, ErrNoMatchingBracketCount) {
+		t.Errorf("got error %v, wanted a parse error on line 2", err)
+	}
+}
@@ -1,57 +1,225 @@
 package gitlabcodeowners
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-func parseFile(reader io.Reader) ([]section, error) {
-	sections := []section{}
-	currentSection := section{
-		name:      "",
-		approvals: 1,
-		owners:    []string{},
-		rules:     []rule{},
+// source describes where a section header or a rule was read from. It is
+// used to write unchanged lines exactly as they were read.
+type source struct {
+	// line is the number of the line starting at 1, it is 0 if the
+	// section or rule was not read from a file.
+	line int
+
+	// raw is the line as it was read, it is empty if the section or rule
+	// was modified after parsing and therefore needs to be rendered again.
+	raw string
+
+	// leading contains the comments and empty lines above the line.
+	leading []string
+}
+
+// lines returns the leading lines and the line itself if it was read from a file.
+func (s source) lines() []string {
+	if s.line == 0 {
+		return append([]string(nil), s.leading...)
 	}
 
-	scanner := bufio.NewScanner(reader)
+	return append(append([]string(nil), s.leading...), s.raw)
+}
+
+func parseFile(reader io.Reader, config parseConfig) (File, error) {
+	sections := []section{}
+	currentSection := newSection("", 1, false, []string{})
+	collector := errorCollector{strict: config.strict, errors: nil}
+	aliases := aliases{}
+
+	var pending []string
+
+	lineNumber := 0
+
+	scanner := newLineScanner(reader)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		lineNumber++
 
-		// skip empty lines
-		if line == "" {
-			continue
+		if err := collector.add(checkUTF8(lineNumber, raw)); err != nil {
+			return emptyFile(), err
 		}
 
-		// skip line if it is a comment
-		if strings.HasPrefix(line, "#") {
+		// skip empty lines and comments, but keep them for the writer
+		if line == "" || strings.HasPrefix(line, "#") {
+			pending = append(pending, raw)
+
+			name, owners, ok, err := aliases.parseAliasDirective(line)
+			if err != nil {
+				if err := collector.add(newParseError(lineNumber, raw, 0, err)); err != nil {
+					return emptyFile(), err
+				}
+			} else if ok {
+				aliases[name] = owners
+			}
+
 			continue
 		}
 
 		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
-			nextSection, err := parseSectionHeader(line)
+			nextSection, err := parseSectionHeaderLine(line)
 			if err == nil {
+				nextSection.owners, nextSection.aliasedOwners = aliases.expand(nextSection.owners)
+
+				// keep the header of a section without rules, because
+				// such a section is not added to the list of sections
+				if len(currentSection.rules) == 0 {
+					pending = append(currentSection.src.lines(), pending...)
+				}
+
+				nextSection.src = source{line: lineNumber, raw: raw, leading: pending}
+				pending = nil
+
 				sections = appendSection(sections, currentSection)
 				currentSection = nextSection
 
 				continue
 			}
 
+			parseErr := newParseError(lineNumber, raw, sectionHeaderErrorOffset(line, err), err)
+			if err := collector.add(parseErr); err != nil {
+				return emptyFile(), err
+			}
+
 			// fall through to rule parsing, because an unparsable
 			// section is treated as rule as described here:
 			// https://docs.gitlab.com/ee/user/project/codeowners/reference.html#unparsable-sections
 		} //nolint:wsl // explain fallthrough behavior
 
-		currentSection.rules = append(currentSection.rules, parseRule(line))
+		content, comment := splitInlineComment(line, 0)
+
+		rule, err := parseRule(content)
+		if err != nil {
+			if err := collector.add(newParseError(lineNumber, raw, 0, err)); err != nil {
+				return emptyFile(), err
+			}
+
+			// keep the line for the writer like a comment
+			pending = append(pending, raw)
+
+			continue
+		}
+
+		rule.comment = comment
+		rule.owners, rule.aliasedOwners = aliases.expand(rule.owners)
+		rule.src = source{line: lineNumber, raw: raw, leading: pending}
+		pending = nil
+
+		currentSection.rules = append(currentSection.rules, rule)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return []section{}, fmt.Errorf("error reading the file content %w", err)
+		return emptyFile(), newReadError(lineNumber+1, err)
+	}
+
+	if len(currentSection.rules) == 0 {
+		pending = append(currentSection.src.lines(), pending...)
+	}
+
+	return File{
+		sections:      appendSection(sections, currentSection),
+		trailing:      pending,
+		parseErrors:   collector.errors,
+		byteOrderMark: scanner.byteOrderMark,
+		crlf:          scanner.crlf,
+	}, nil
+}
+
+// parseSectionHeaderLine parses a trimmed line with a section header and an
+// optional inline comment.
+func parseSectionHeaderLine(line string) (section, error) {
+	// a `#` within the brackets is part of the section name
+	header, comment := splitInlineComment(line, sectionHeaderEnd(line))
+
+	sec, err := parseSectionHeader(header)
+	if err != nil {
+		return section{}, err
+	}
+
+	sec.comment = comment
+
+	return sec, nil
+}
+
+// splitInlineComment splits a trimmed line into its content and the text of
+// a trailing comment. Like GitLab, a `#` starts a comment if it is preceded
+// by an unescaped whitespace, the search starts at the byte offset `start`.
+func splitInlineComment(line string, start int) (content, comment string) { //nolint:nonamedreturns // name the returned strings
+	for i := max(start, 1); i < len(line); i++ {
+		if line[i] != '#' || !isUnescapedSpace(line, i-1) {
+			continue
+		}
+
+		return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
 	}
 
-	return appendSection(sections, currentSection), nil
+	return line, ""
+}
+
+func isUnescapedSpace(line string, i int) bool {
+	return (line[i] == ' ' || line[i] == '\t') && (i == 0 || line[i-1] != '\\')
+}
+
+// sizeLimitReader fails with `ErrFileTooLarge` as soon as more than
+// `limit` bytes are read from the underlying reader.
+type sizeLimitReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (r *sizeLimitReader) Read(buffer []byte) (int, error) {
+	count, err := r.reader.Read(buffer)
+	r.read += int64(count)
+
+	if r.read > r.limit {
+		return count, fmt.Errorf("%w: more than %d bytes", ErrFileTooLarge, r.limit)
+	}
+
+	return count, err //nolint:wrapcheck // pass io.EOF to the scanner
+}
+
+func emptyFile() File {
+	return File{sections: []section{}, trailing: nil, parseErrors: nil, byteOrderMark: false, crlf: false}
+}
+
+// newParseError returns an error for the given line, where `offset` is the
+// byte offset of the problem within the trimmed line.
+func newParseError(lineNumber int, raw string, offset int, err error) *ParseError {
+	indentation := len(raw) - len(strings.TrimLeftFunc(raw, unicode.IsSpace))
+
+	return &ParseError{
+		Line:   lineNumber,
+		Column: utf8.RuneCountInString(raw[:indentation+offset]) + 1,
+		Text:   withoutLineEnding(raw),
+		Err:    err,
+	}
+}
+
+func newReadError(lineNumber int, err error) *ParseError {
+	if !errors.Is(err, ErrFileTooLarge) {
+		err = fmt.Errorf("%w: %w", ErrReadFailed, err)
+	}
+
+	return &ParseError{
+		Line:   lineNumber,
+		Column: 0,
+		Text:   "",
+		Err:    err,
+	}
 }
 
 func appendSection(sections []section, section section) []section {
@@ -61,6 +229,12 @@ func appendSection(sections []section, section section) []section {
 
 	for i, s := range sections {
 		if strings.EqualFold(s.name, section.name) {
+			// keep the header of the merged section in front of its first
+			// rule, so the writer can reproduce the original file
+			if lines := section.src.lines(); len(lines) > 0 {
+				section.rules[0].src.leading = append(lines, section.rules[0].src.leading...)
+			}
+
 			// only merge the rules into the previous section
 			// ignore everything else from the new section.
 			// https://docs.gitlab.com/ee/user/project/codeowners/#sections-with-duplicate-names
@@ -72,3 +246,84 @@ func appendSection(sections []section, section section) []section {
 
 	return append(sections, section)
 }
+
+// parseRulesFile parses a file without sections where every line is turned
+// into a rule by the given function. Lines for which no rule is returned are
+// kept like comments and empty lines, if an error is returned for such a
+// line it is reported as `ParseError` at the beginning of the line.
+func parseRulesFile(
+	reader io.Reader,
+	config parseConfig,
+	parseLine func(line string) (rule, bool, error),
+) (File, error) {
+	rules := []rule{}
+	collector := errorCollector{strict: config.strict, errors: nil}
+
+	var pending []string
+
+	lineNumber := 0
+
+	scanner := newLineScanner(reader)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		lineNumber++
+
+		if err := collector.add(checkUTF8(lineNumber, raw)); err != nil {
+			return emptyFile(), err
+		}
+
+		rule, ok, err := parseLine(raw)
+		if err != nil {
+			if err := collector.add(newParseError(lineNumber, raw, 0, err)); err != nil {
+				return emptyFile(), err
+			}
+		}
+
+		if !ok {
+			pending = append(pending, raw)
+
+			continue
+		}
+
+		rule.src = source{line: lineNumber, raw: raw, leading: pending}
+		pending = nil
+
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return emptyFile(), newReadError(lineNumber+1, err)
+	}
+
+	defaultSection := newSection("", 1, false, []string{})
+	defaultSection.rules = rules
+
+	return File{
+		sections:      appendSection([]section{}, defaultSection),
+		trailing:      pending,
+		parseErrors:   collector.errors,
+		byteOrderMark: scanner.byteOrderMark,
+		crlf:          scanner.crlf,
+	}, nil
+}
+
+// errorCollector collects the problems found while parsing a file.
+type errorCollector struct {
+	strict bool
+	errors []*ParseError
+}
+
+// add records the given problem, in strict mode the problem is returned
+// instead, because parsing has to stop.
+func (c *errorCollector) add(parseErr *ParseError) error {
+	switch {
+	case parseErr == nil:
+		return nil
+	case c.strict:
+		return parseErr
+	}
+
+	c.errors = append(c.errors, parseErr)
+
+	return nil
+}
@@ -2,6 +2,7 @@ package gitlabcodeowners
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -23,145 +24,178 @@ func TestParser_parseFile(t *testing.T) {
 	tests := []struct {
 		name    string
 		reader  io.Reader
-		want    []section
+		want    File
 		wantErr bool
 	}{
 		{
 			name:    "empty file",
 			reader:  strings.NewReader(""),
-			want:    []section{},
+			want:    File{sections: []section{}, trailing: nil},
 			wantErr: false,
 		},
 		{
 			name:   "without sections",
 			reader: strings.NewReader("# A comment\n*.md @doc-team\n\nterms.md @legal-team"),
-			want: []section{
-				{
-					name:      "",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "*.md", normalized: "/**/*.md"},
-							owners:  []string{"@doc-team"},
-						},
-						{
-							pattern: pattern{value: "terms.md", normalized: "/**/terms.md"},
-							owners:  []string{"@legal-team"},
+			want: File{
+				sections: []section{
+					{
+						name:      "",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("*.md", "/**/*.md"),
+								owners:  []string{"@doc-team"},
+								src:     source{line: 2, raw: "*.md @doc-team", leading: []string{"# A comment"}},
+							},
+							{
+								pattern: wantPattern("terms.md", "/**/terms.md"),
+								owners:  []string{"@legal-team"},
+								src:     source{line: 4, raw: "terms.md @legal-team", leading: []string{""}},
+							},
 						},
 					},
 				},
+				trailing: nil,
 			},
 			wantErr: false,
 		},
 		{
 			name:   "with sections",
 			reader: strings.NewReader("[README Owners]\nREADME.md @user1 @user2\ninternal/README.md @user4\n\n[README other owners]\nREADME.md @user3"),
-			want: []section{
-				{
-					name:      "README Owners",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "README.md", normalized: "/**/README.md"},
-							owners:  []string{"@user1", "@user2"},
-						},
-						{
-							pattern: pattern{value: "internal/README.md", normalized: "/**/internal/README.md"},
-							owners:  []string{"@user4"},
+			want: File{
+				sections: []section{
+					{
+						name:      "README Owners",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("README.md", "/**/README.md"),
+								owners:  []string{"@user1", "@user2"},
+								src:     source{line: 2, raw: "README.md @user1 @user2", leading: nil},
+							},
+							{
+								pattern: wantPattern("internal/README.md", "/**/internal/README.md"),
+								owners:  []string{"@user4"},
+								src:     source{line: 3, raw: "internal/README.md @user4", leading: nil},
+							},
 						},
+						src: source{line: 1, raw: "[README Owners]", leading: nil},
 					},
-				},
-				{
-					name:      "README other owners",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "README.md", normalized: "/**/README.md"},
-							owners:  []string{"@user3"},
+					{
+						name:      "README other owners",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("README.md", "/**/README.md"),
+								owners:  []string{"@user3"},
+								src:     source{line: 6, raw: "README.md @user3", leading: nil},
+							},
 						},
+						src: source{line: 5, raw: "[README other owners]", leading: []string{""}},
 					},
 				},
+				trailing: nil,
 			},
 			wantErr: false,
 		},
 		{
 			name:   "with optional sections, approval count and default owners",
 			reader: strings.NewReader("[Documentation][2] @docs-team\ndocs/\nREADME.md\n\n^[Database] @database-team\nmodel/db/\nconfig/db/database-setup.md @docs-team"),
-			want: []section{
-				{
-					name:      "Documentation",
-					approvals: 2,
-					owners:    []string{"@docs-team"},
-					rules: []rule{
-						{
-							pattern: pattern{value: "docs/", normalized: "/**/docs/**/*"},
-							owners:  []string{},
-						},
-						{
-							pattern: pattern{value: "README.md", normalized: "/**/README.md"},
-							owners:  []string{},
+			want: File{
+				sections: []section{
+					{
+						name:      "Documentation",
+						approvals: 2,
+						owners:    []string{"@docs-team"},
+						rules: []rule{
+							{
+								pattern: wantPattern("docs/", "/**/docs/**/*"),
+								owners:  []string{},
+								src:     source{line: 2, raw: "docs/", leading: nil},
+							},
+							{
+								pattern: wantPattern("README.md", "/**/README.md"),
+								owners:  []string{},
+								src:     source{line: 3, raw: "README.md", leading: nil},
+							},
 						},
+						src: source{line: 1, raw: "[Documentation][2] @docs-team", leading: nil},
 					},
-				},
-				{
-					name:      "Database",
-					approvals: 0,
-					owners:    []string{"@database-team"},
-					rules: []rule{
-						{
-							pattern: pattern{value: "model/db/", normalized: "/**/model/db/**/*"},
-							owners:  []string{},
-						},
-						{
-							pattern: pattern{value: "config/db/database-setup.md", normalized: "/**/config/db/database-setup.md"},
-							owners:  []string{"@docs-team"},
+					{
+						name:      "Database",
+						approvals: 0,
+						optional:  true,
+						owners:    []string{"@database-team"},
+						rules: []rule{
+							{
+								pattern: wantPattern("model/db/", "/**/model/db/**/*"),
+								owners:  []string{},
+								src:     source{line: 6, raw: "model/db/", leading: nil},
+							},
+							{
+								pattern: wantPattern("config/db/database-setup.md", "/**/config/db/database-setup.md"),
+								owners:  []string{"@docs-team"},
+								src:     source{line: 7, raw: "config/db/database-setup.md @docs-team", leading: nil},
+							},
 						},
+						src: source{line: 5, raw: "^[Database] @database-team", leading: []string{""}},
 					},
 				},
+				trailing: nil,
 			},
 			wantErr: false,
 		},
 		{
 			name:   "duplicate section names",
 			reader: strings.NewReader("[Documentation]\nee/docs/ @docs\ndocs/ @docs\n\n[Database]\nREADME.md @database\nmodel/db/ @database\n\n[DOCUMENTATION]\nREADME.md  @docs"),
-			want: []section{
-				{
-					name:      "Documentation",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "ee/docs/", normalized: "/**/ee/docs/**/*"},
-							owners:  []string{"@docs"},
-						},
-						{
-							pattern: pattern{value: "docs/", normalized: "/**/docs/**/*"},
-							owners:  []string{"@docs"},
-						},
-						{
-							pattern: pattern{value: "README.md", normalized: "/**/README.md"},
-							owners:  []string{"@docs"},
+			want: File{
+				sections: []section{
+					{
+						name:      "Documentation",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("ee/docs/", "/**/ee/docs/**/*"),
+								owners:  []string{"@docs"},
+								src:     source{line: 2, raw: "ee/docs/ @docs", leading: nil},
+							},
+							{
+								pattern: wantPattern("docs/", "/**/docs/**/*"),
+								owners:  []string{"@docs"},
+								src:     source{line: 3, raw: "docs/ @docs", leading: nil},
+							},
+							{
+								pattern: wantPattern("README.md", "/**/README.md"),
+								owners:  []string{"@docs"},
+								src:     source{line: 10, raw: "README.md  @docs", leading: []string{"", "[DOCUMENTATION]"}},
+							},
 						},
+						src: source{line: 1, raw: "[Documentation]", leading: nil},
 					},
-				},
-				{
-					name:      "Database",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "README.md", normalized: "/**/README.md"},
-							owners:  []string{"@database"},
-						},
-						{
-							pattern: pattern{value: "model/db/", normalized: "/**/model/db/**/*"},
-							owners:  []string{"@database"},
+					{
+						name:      "Database",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("README.md", "/**/README.md"),
+								owners:  []string{"@database"},
+								src:     source{line: 6, raw: "README.md @database", leading: nil},
+							},
+							{
+								pattern: wantPattern("model/db/", "/**/model/db/**/*"),
+								owners:  []string{"@database"},
+								src:     source{line: 7, raw: "model/db/ @database", leading: nil},
+							},
 						},
+						src: source{line: 5, raw: "[Database]", leading: []string{""}},
 					},
 				},
+				trailing: nil,
 			},
 			wantErr: false,
 		},
@@ -169,33 +203,65 @@ func TestParser_parseFile(t *testing.T) {
 			// https://docs.gitlab.com/ee/user/project/codeowners/reference.html#unparsable-sections
 			name:   "unparsable section example",
 			reader: strings.NewReader("* @group\n\n[Section name\ndocs/ @docs_group"),
-			want: []section{
-				{
-					name:      "",
-					approvals: 1,
-					owners:    []string{},
-					rules: []rule{
-						{
-							pattern: pattern{value: "*", normalized: "/**/*"},
-							owners:  []string{"@group"},
-						},
-						{
-							pattern: pattern{value: "[Section", normalized: "/**/[Section"},
-							owners:  []string{"name"},
+			want: File{
+				sections: []section{
+					{
+						name:      "",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("*", "/**/*"),
+								owners:  []string{"@group"},
+								src:     source{line: 1, raw: "* @group", leading: nil},
+							},
+							{
+								pattern: wantPattern("[Section", "/**/[Section"),
+								owners:  []string{"name"},
+								src:     source{line: 3, raw: "[Section name", leading: []string{""}},
+							},
+							{
+								pattern: wantPattern("docs/", "/**/docs/**/*"),
+								owners:  []string{"@docs_group"},
+								src:     source{line: 4, raw: "docs/ @docs_group", leading: nil},
+							},
 						},
-						{
-							pattern: pattern{value: "docs/", normalized: "/**/docs/**/*"},
-							owners:  []string{"@docs_group"},
+					},
+				},
+				trailing: nil,
+				parseErrors: []*ParseError{
+					{Line: 3, Column: 1, Text: "[Section name", Err: ErrNoMatchingBracketCount},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "empty sections and trailing comments",
+			reader: strings.NewReader("[Empty]\n\n[Docs]\ndocs/ @docs\n# the end\n"),
+			want: File{
+				sections: []section{
+					{
+						name:      "Docs",
+						approvals: 1,
+						owners:    []string{},
+						rules: []rule{
+							{
+								pattern: wantPattern("docs/", "/**/docs/**/*"),
+								owners:  []string{"@docs"},
+								src:     source{line: 4, raw: "docs/ @docs", leading: nil},
+							},
 						},
+						src: source{line: 3, raw: "[Docs]", leading: []string{"[Empty]", ""}},
 					},
 				},
+				trailing: []string{"# the end"},
 			},
 			wantErr: false,
 		},
 		{
 			name:    "error while reading",
 			reader:  errorReader{},
-			want:    []section{},
+			want:    File{sections: []section{}, trailing: nil},
 			wantErr: true,
 		},
 	}
@@ -206,7 +272,7 @@ func TestParser_parseFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := parseFile(tt.reader)
+			got, err := parseFile(tt.reader, newParseConfig(nil))
 
 			testhelper.DeepEqual(t, got, tt.want)
 
@@ -322,3 +388,200 @@ func TestParser_appendSection(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_longLines(t *testing.T) {
+	t.Parallel()
+
+	owners := make([]string, 20000)
+	for i := range owners {
+		owners[i] = fmt.Sprintf("@user%d", i)
+	}
+
+	content := "[Docs] " + strings.Join(owners, " ") + "\n*.md " + strings.Join(owners, " ") + "\n"
+	if len(content) < 2*64*1024 {
+		t.Fatalf("test content is too short: %d", len(content))
+	}
+
+	for _, dialect := range []Dialect{DialectGitLab, DialectGitea, DialectBitbucket} {
+		file, err := NewCodeOwnersFile(strings.NewReader(content), WithDialect(dialect))
+		if err != nil {
+			t.Fatalf("Failed to parse long lines with dialect %s: %v", dialect, err)
+		}
+
+		if dialect != DialectGitLab {
+			continue
+		}
+
+		approval := file.GetRequiredApprovalsForFile("/README.md")["Docs"]
+		if len(approval.Owners) != len(owners) {
+			t.Errorf("got %d owners, wanted %d", len(approval.Owners), len(owners))
+		}
+
+		if got := file.String(); got != content {
+			t.Errorf("written file differs from the parsed content")
+		}
+	}
+}
+
+func TestParser_maxFileSize(t *testing.T) {
+	t.Parallel()
+
+	content := "* @general\n[Docs]\n*.md @docs\n"
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "no limit", limit: 0, wantErr: false},
+		{name: "exact size", limit: int64(len(content)), wantErr: false},
+		{name: "one byte too large", limit: int64(len(content) - 1), wantErr: true},
+		{name: "gitlab limit", limit: GitLabMaxFileSize, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewCodeOwnersFile(strings.NewReader(content), WithMaxFileSize(tt.limit))
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%t but got error %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr && (!errors.Is(err, ErrFileTooLarge) || errors.Is(err, ErrReadFailed)) {
+				t.Errorf("got error %v, wanted only %v", err, ErrFileTooLarge)
+			}
+		})
+	}
+}
+
+func TestParser_gitLabMaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	content := "*.md @docs\n# " + strings.Repeat("-", GitLabMaxFileSize) + "\n"
+
+	_, err := NewCodeOwnersFile(strings.NewReader(content), WithMaxFileSize(GitLabMaxFileSize))
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("got error %v, wanted %v", err, ErrFileTooLarge)
+	}
+}
+
+func TestParser_splitInlineComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		line        string
+		start       int
+		wantContent string
+		wantComment string
+	}{
+		{
+			name:        "no comment",
+			line:        "/api/ @backend",
+			wantContent: "/api/ @backend",
+			wantComment: "",
+		},
+		{
+			name:        "comment after owners",
+			line:        "/api/ @backend # owned since Q3",
+			wantContent: "/api/ @backend",
+			wantComment: "owned since Q3",
+		},
+		{
+			name:        "comment without owners",
+			line:        "/api/\t#no owners",
+			wantContent: "/api/",
+			wantComment: "no owners",
+		},
+		{
+			name:        "pound within a token",
+			line:        "file#1.md @docs",
+			wantContent: "file#1.md @docs",
+			wantComment: "",
+		},
+		{
+			name:        "escaped pound",
+			line:        "docs/ \\#not-a-comment.md @docs",
+			wantContent: "docs/ \\#not-a-comment.md @docs",
+			wantComment: "",
+		},
+		{
+			name:        "escaped whitespace before pound",
+			line:        "file\\ #1.md @docs",
+			wantContent: "file\\ #1.md @docs",
+			wantComment: "",
+		},
+		{
+			name:        "pound within the brackets of a header",
+			line:        "[C# code] @dotnet # legacy",
+			start:       len("[C# code]"),
+			wantContent: "[C# code] @dotnet",
+			wantComment: "legacy",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, comment := splitInlineComment(tt.line, tt.start)
+
+			if content != tt.wantContent || comment != tt.wantComment {
+				t.Errorf("got (%q, %q), wanted (%q, %q)", content, comment, tt.wantContent, tt.wantComment)
+			}
+		})
+	}
+}
+
+func TestParser_inlineCommentWithBrackets(t *testing.T) {
+	t.Parallel()
+
+	content := "[Docs] @docs # see [wiki]\n*.md\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(content), WithStrictParsing())
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	testhelper.DeepEqual(t, file.sections[0].comment, "see [wiki]")
+
+	got := file.GetRequiredApprovalsForFile("/README.md")
+	want := map[string]Approval{
+		"Docs": {Pattern: "*.md", Approvals: 1, Owners: []string{"@docs"}},
+	}
+	testhelper.DeepEqual(t, got, want)
+}
+
+func TestParser_inlineComments(t *testing.T) {
+	t.Parallel()
+
+	content := "[C# code][2] @dotnet # reviewed weekly\n/api/ @backend # owned since Q3\n*.cs\n"
+
+	file, err := NewCodeOwnersFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	testhelper.DeepEqual(t, file.sections[0].comment, "reviewed weekly")
+
+	got := file.GetRequiredApprovalsForFile("/api/v1/users.go")
+	want := map[string]Approval{
+		"C# code": {
+			Pattern:   "/api/",
+			Approvals: 2,
+			Owners:    []string{"@backend"},
+			Comment:   "owned since Q3",
+		},
+	}
+	testhelper.DeepEqual(t, got, want)
+
+	if got := file.String(); got != content {
+		t.Errorf("got %q, wanted %q", got, content)
+	}
+}
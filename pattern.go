@@ -4,26 +4,85 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-
-	doublestar "github.com/bmatcuk/doublestar/v4"
 )
 
 type pattern struct {
 	value      string
 	normalized string
+
+	// globs contains the normalized pattern with expanded braces, so they
+	// are only expanded once and not for every matched path.
+	globs [][]rune
+
+	regex     *regexp.Regexp
+	regexFold *regexp.Regexp
+	negated   bool
 }
 
-func (p pattern) match(path string) bool {
-	matched, err := doublestar.Match(p.normalized, path)
+func (p pattern) match(path string, caseFold bool) bool {
+	if p.regex != nil {
+		regex := p.regex
+		if caseFold {
+			regex = p.regexFold
+		}
+
+		// regular expressions are matched against the path without
+		// the leading `/` and the result is inverted for negated patterns
+		return regex.MatchString(strings.TrimPrefix(path, "/")) != p.negated
+	}
 
-	return err == nil && matched
+	return fnmatchGlobs(p.globs, path, caseFold)
 }
 
+// newPattern returns the pattern for the value, if its braces expand to
+// too many patterns only the first ones are matched.
 func newPattern(value string) pattern {
+	pattern, _ := newGlobPattern(value)
+
+	return pattern
+}
+
+// parsePattern returns the pattern for the value of a parsed rule, it fails
+// if the braces of the pattern expand to too many patterns.
+func parsePattern(value string) (pattern, error) {
+	pattern, complete := newGlobPattern(value)
+	if !complete {
+		return pattern, fmt.Errorf("%w: '%s'", ErrPatternTooComplex, value)
+	}
+
+	return pattern, nil
+}
+
+func newGlobPattern(value string) (pattern, bool) {
+	normalized := normalizePattern(value)
+	globs, complete := expandGlobs(normalized)
+
 	return pattern{
 		value:      value,
-		normalized: normalizePattern(value),
+		normalized: normalized,
+		globs:      globs,
+		regex:      nil,
+		regexFold:  nil,
+		negated:    false,
+	}, complete
+}
+
+func newRegexPattern(value string) (pattern, error) {
+	expression := strings.TrimPrefix(value, "!")
+
+	regex, err := regexp.Compile(fmt.Sprintf("^%s$", expression))
+	if err != nil {
+		return pattern{}, fmt.Errorf("failed to compile pattern '%s': %w", value, err)
 	}
+
+	return pattern{
+		value:      value,
+		normalized: regex.String(),
+		globs:      nil,
+		regex:      regex,
+		regexFold:  regexp.MustCompile("(?i)" + regex.String()),
+		negated:    strings.HasPrefix(value, "!"),
+	}, nil
 }
 
 func normalizePattern(pattern string) string {
@@ -49,3 +108,34 @@ func normalizePattern(pattern string) string {
 
 	return pattern
 }
+
+// Pattern is a pattern of a rule in the Gitlab syntax, which can be used to
+// test which paths a rule would match.
+type Pattern struct {
+	pattern pattern
+}
+
+// NewPattern returns the pattern for the given value like `docs/` or `*.md`.
+func NewPattern(value string) Pattern {
+	return Pattern{pattern: newPattern(value)}
+}
+
+// Value returns the pattern as it was given.
+func (p Pattern) Value() string {
+	return p.pattern.value
+}
+
+// Normalized returns the glob which is matched against the paths, for
+// example `/**/docs/**/*` for the pattern `docs/`.
+func (p Pattern) Normalized() string {
+	return p.pattern.normalized
+}
+
+// Match reports if the pattern matches the file given by its path. The path
+// needs to start with a `/` unless the `WithPathNormalization` option is
+// used, the `WithCaseInsensitiveMatching` option is supported as well.
+func (p Pattern) Match(path string, options ...QueryOption) bool {
+	config := newQueryConfig(options)
+
+	return p.pattern.match(config.normalizePath(path), config.caseInsensitive)
+}
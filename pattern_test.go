@@ -202,7 +202,7 @@ func TestPattern_match(t *testing.T) {
 			t.Parallel()
 
 			for _, ex := range tt.examples {
-				got := newPattern(tt.pattern).match(ex.path)
+				got := newPattern(tt.pattern).match(ex.path, false)
 
 				if got != ex.want {
 					t.Errorf("path %s -> got %t, wanted %t", ex.path, got, ex.want)
@@ -271,3 +271,45 @@ func TestPattern_normalizePattern(t *testing.T) {
 		})
 	}
 }
+
+func TestPattern_Pattern(t *testing.T) {
+	t.Parallel()
+
+	pattern := NewPattern("docs/")
+
+	if got, want := pattern.Value(), "docs/"; got != want {
+		t.Errorf("got value %s, wanted %s", got, want)
+	}
+
+	if got, want := pattern.Normalized(), "/**/docs/**/*"; got != want {
+		t.Errorf("got normalized value %s, wanted %s", got, want)
+	}
+
+	tests := []struct {
+		path    string
+		options []QueryOption
+		want    bool
+	}{
+		{path: "/docs/README.md", want: true},
+		{path: "/src/docs/api.md", want: true},
+		{path: "/README.md", want: false},
+		{path: "docs/README.md", want: false},
+		{path: "docs/README.md", options: []QueryOption{WithPathNormalization()}, want: true},
+		{path: "/DOCS/README.md", want: false},
+		{path: "/DOCS/README.md", options: []QueryOption{WithCaseInsensitiveMatching()}, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := pattern.Match(tt.path, tt.options...); got != tt.want {
+			t.Errorf("path %s -> got %t, wanted %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+// wantPattern returns the pattern which is expected for the value and its
+// normalized form.
+func wantPattern(value, normalized string) pattern {
+	globs, _ := expandGlobs(normalized)
+
+	return pattern{value: value, normalized: normalized, globs: globs}
+}
@@ -0,0 +1,402 @@
+package gitlabcodeowners
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPolicy is returned if a policy can not be read or contains an
+// invalid rule.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// PolicyKind describes what a policy rule requires.
+type PolicyKind string
+
+const (
+	// PolicyMinApprovals requires the paths to be in a required section with
+	// at least `approvals` approvals.
+	PolicyMinApprovals PolicyKind = "min-approvals"
+
+	// PolicyRequiredOwners requires the paths to be owned by all `owners` in
+	// a required section.
+	PolicyRequiredOwners PolicyKind = "required-owners"
+
+	// PolicyMinGroups requires every section to list at least `groups` groups
+	// as owners of the section or its rules.
+	PolicyMinGroups PolicyKind = "min-groups"
+)
+
+// defaultGroupPattern matches nested Gitlab groups like `@org/team` and
+// Bitbucket reviewer groups like `@@team`. A top-level group like `@security`
+// can not be told apart from a user and has to be listed in the
+// `group-owners` of a rule.
+var defaultGroupPattern = regexp.MustCompile(`^@(@|[^/]+/)`)
+
+// Policy contains the ownership requirements which a `CODEOWNERS` file has
+// to meet. Policies are written in JSON, for example:
+//
+//	{"rules": [
+//	  {"name": "infra", "kind": "min-approvals", "paths": ["/infra/**"], "approvals": 2},
+//	  {"name": "self", "kind": "required-owners", "paths": ["/CODEOWNERS"], "owners": ["@security"]},
+//	  {"name": "groups", "kind": "min-groups", "groups": 1}
+//	]}
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule is a single requirement of a policy.
+type PolicyRule struct {
+	// Name identifies the rule in the reported diagnostics.
+	Name string `json:"name"`
+
+	Kind PolicyKind `json:"kind"`
+
+	// Description explains the rule and is added to the reported messages.
+	Description string `json:"description"`
+
+	// Severity of the reported diagnostics, by default `SeverityError`.
+	Severity Severity `json:"severity"`
+
+	// Paths contains the patterns of the paths the rule applies to, they
+	// are matched like the patterns in a Gitlab `CODEOWNERS` file. A
+	// pattern ending with `/**` matches all files below the directory.
+	Paths []string `json:"paths"`
+
+	// Approvals is the minimal approval count for `PolicyMinApprovals`.
+	Approvals int `json:"approvals"`
+
+	// Owners contains the owners required by `PolicyRequiredOwners`.
+	Owners []string `json:"owners"`
+
+	// Groups is the minimal number of groups for `PolicyMinGroups`.
+	Groups int `json:"groups"`
+
+	// GroupPattern is a regular expression which matches the owners which
+	// are groups, by default nested groups like `@org/team` and groups
+	// like `@@team` are matched.
+	GroupPattern string `json:"group-pattern"`
+
+	// GroupOwners contains owners which are groups in addition to the
+	// owners matched by the group pattern, for example top-level groups
+	// like `@security`. They are compared case-insensitively.
+	GroupOwners []string `json:"group-owners"`
+
+	groupRegex *regexp.Regexp
+	patterns   []pattern
+}
+
+// ReadPolicy reads and validates a policy in the JSON format.
+func ReadPolicy(reader io.Reader) (Policy, error) {
+	policy := Policy{Rules: nil}
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&policy); err != nil {
+		return Policy{}, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+	}
+
+	for i := range policy.Rules {
+		if err := policy.Rules[i].validate(); err != nil {
+			return Policy{}, err
+		}
+	}
+
+	return policy, nil
+}
+
+func (r *PolicyRule) validate() error {
+	var problem string
+
+	switch {
+	case r.Name == "":
+		problem = "missing name"
+	case r.Severity != "" && r.Severity != SeverityError && r.Severity != SeverityWarning && r.Severity != SeverityInfo:
+		problem = fmt.Sprintf("invalid severity '%s'", r.Severity)
+	case r.Kind == PolicyMinApprovals && (len(r.Paths) == 0 || r.Approvals < 1):
+		problem = "paths and a positive approval count are required"
+	case r.Kind == PolicyRequiredOwners && (len(r.Paths) == 0 || len(r.Owners) == 0):
+		problem = "paths and owners are required"
+	case r.Kind == PolicyMinGroups && r.Groups < 1:
+		problem = "a positive group count is required"
+	case r.Kind != PolicyMinApprovals && r.Kind != PolicyRequiredOwners && r.Kind != PolicyMinGroups:
+		problem = fmt.Sprintf("unknown kind '%s'", r.Kind)
+	}
+
+	if problem != "" {
+		return fmt.Errorf("%w: rule '%s': %s", ErrInvalidPolicy, r.Name, problem)
+	}
+
+	if r.Severity == "" {
+		r.Severity = SeverityError
+	}
+
+	r.groupRegex = defaultGroupPattern
+	r.patterns = make([]pattern, 0, len(r.Paths))
+
+	for _, path := range r.Paths {
+		// a directory pattern matches all files below the directory
+		if strings.HasSuffix(path, "/**") {
+			path = strings.TrimSuffix(path, "**")
+		}
+
+		r.patterns = append(r.patterns, newPattern(path))
+	}
+
+	if r.GroupPattern != "" {
+		regex, err := regexp.Compile(r.GroupPattern)
+		if err != nil {
+			return fmt.Errorf("%w: rule '%s': %w", ErrInvalidPolicy, r.Name, err)
+		}
+
+		r.groupRegex = regex
+	}
+
+	return nil
+}
+
+// Evaluate checks the file against all rules of the policy. The rules for
+// paths are checked for all files in `fsys` which match their patterns, if
+// `fsys` is nil only the patterns without wildcards are checked as paths and
+// the other patterns are reported as unchecked with `SeverityInfo`. Each
+// violation is reported with the check `policy:<name>`.
+func (p Policy) Evaluate(file File, fsys fs.FS) ([]Diagnostic, error) {
+	paths, err := policyPaths(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics := []Diagnostic{}
+
+	for _, rule := range p.Rules {
+		if rule.groupRegex == nil {
+			if err := rule.validate(); err != nil {
+				return nil, err
+			}
+		}
+
+		pass := &Pass{
+			File:        file,
+			Now:         time.Time{},
+			check:       rule.check(),
+			severity:    rule.Severity,
+			diagnostics: nil,
+		}
+
+		switch rule.Kind {
+		case PolicyMinApprovals, PolicyRequiredOwners:
+			if paths == nil {
+				rule.reportUncheckedPatterns(pass)
+			}
+
+			for _, path := range rule.matchingPaths(paths) {
+				rule.evaluatePath(pass, path)
+			}
+		case PolicyMinGroups:
+			rule.evaluateGroups(pass)
+		}
+
+		diagnostics = append(diagnostics, pass.diagnostics...)
+	}
+
+	return diagnostics, nil
+}
+
+// Checks returns a check for each rule of the policy, which describes the
+// diagnostics reported for the rule. The checks can not be run by a linter.
+func (p Policy) Checks() []Check {
+	checks := make([]Check, 0, len(p.Rules))
+
+	for _, rule := range p.Rules {
+		checks = append(checks, rule.check())
+	}
+
+	return checks
+}
+
+func (r PolicyRule) check() Check {
+	severity := r.Severity
+	if severity == "" {
+		severity = SeverityError
+	}
+
+	return Check{Name: "policy:" + r.Name, Doc: r.Description, Severity: severity, Enabled: true, Run: nil}
+}
+
+func policyPaths(fsys fs.FS) ([]string, error) {
+	if fsys == nil {
+		return nil, nil
+	}
+
+	paths := []string{}
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return fs.SkipDir
+		}
+
+		if !entry.IsDir() {
+			paths = append(paths, "/"+path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect paths: %w", err)
+	}
+
+	return paths, nil
+}
+
+// matchingPaths returns the paths matching the patterns of the rule, if no
+// paths are given the patterns without wildcards are used as paths.
+func (r PolicyRule) matchingPaths(paths []string) []string {
+	if paths == nil {
+		literal := []string{}
+
+		for _, pattern := range r.Paths {
+			if isLiteralPath(pattern) {
+				literal = append(literal, pattern)
+			}
+		}
+
+		return literal
+	}
+
+	matching := []string{}
+
+	for _, path := range paths {
+		for _, pattern := range r.patterns {
+			if pattern.match(path, false) {
+				matching = append(matching, path)
+
+				break
+			}
+		}
+	}
+
+	return matching
+}
+
+// reportUncheckedPatterns reports the patterns with wildcards, which can not
+// be checked without the files they match.
+func (r PolicyRule) reportUncheckedPatterns(pass *Pass) {
+	for _, pattern := range r.Paths {
+		if !isLiteralPath(pattern) {
+			pass.diagnostics = append(pass.diagnostics, Diagnostic{
+				Check:    pass.check.Name,
+				Severity: SeverityInfo,
+				Line:     0,
+				Column:   0,
+				Message:  fmt.Sprintf("%s was not checked, because no files were given", pattern),
+				Fix:      nil,
+			})
+		}
+	}
+}
+
+func isLiteralPath(pattern string) bool {
+	return !strings.ContainsAny(pattern, "*?[{\\")
+}
+
+func (r PolicyRule) evaluatePath(pass *Pass, path string) {
+	approvals := pass.File.GetRequiredApprovalsForFile(path)
+
+	switch r.Kind {
+	case PolicyMinApprovals:
+		highest := 0
+		for _, approval := range approvals {
+			highest = max(highest, approval.Approvals)
+		}
+
+		if highest < r.Approvals {
+			pass.Report(0, 0, "%s requires a required section with at least %d approvals, but at most %d are required%s",
+				path, r.Approvals, highest, r.suffix())
+		}
+	case PolicyRequiredOwners:
+		for _, owner := range r.Owners {
+			if !isOwnedBy(approvals, owner) {
+				pass.Report(0, 0, "%s must be owned by %s in a required section%s", path, owner, r.suffix())
+			}
+		}
+	case PolicyMinGroups:
+	}
+}
+
+func isOwnedBy(approvals map[string]Approval, owner string) bool {
+	for _, approval := range approvals {
+		if approval.Approvals < 1 {
+			continue
+		}
+
+		for _, candidate := range approval.Owners {
+			if strings.EqualFold(candidate, owner) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r PolicyRule) evaluateGroups(pass *Pass) {
+	for _, sec := range pass.File.sections {
+		groups := map[string]bool{}
+
+		for _, owners := range append([][]string{sec.owners}, ruleOwners(sec.rules)...) {
+			for _, owner := range owners {
+				if r.isGroup(owner) {
+					groups[strings.ToLower(owner)] = true
+				}
+			}
+		}
+
+		if len(groups) < r.Groups {
+			pass.Report(sec.src.line, 0, "section '%s' lists %d groups, but at least %d are required%s",
+				sec.name, len(groups), r.Groups, r.suffix())
+		}
+	}
+}
+
+func (r PolicyRule) isGroup(owner string) bool {
+	if r.groupRegex.MatchString(owner) {
+		return true
+	}
+
+	for _, group := range r.GroupOwners {
+		if strings.EqualFold(group, owner) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleOwners(rules []rule) [][]string {
+	owners := make([][]string, 0, len(rules))
+
+	for _, r := range rules {
+		owners = append(owners, r.owners)
+	}
+
+	return owners
+}
+
+// suffix returns the description of the rule to be appended to a message.
+func (r PolicyRule) suffix() string {
+	if r.Description == "" {
+		return ""
+	}
+
+	return ": " + r.Description
+}
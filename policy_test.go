@@ -0,0 +1,220 @@
+package gitlabcodeowners
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+const policyTestFile = `* @all
+/CODEOWNERS @security
+
+[Infra][2] @org/infra @bob
+/infra/
+
+[Docs] @alice
+/infra/README.md
+docs/
+`
+
+func TestPolicy_ReadPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr bool
+	}{
+		{
+			name:   "valid policy",
+			policy: `{"rules": [{"name": "a", "kind": "min-groups", "groups": 1, "group-pattern": "^@team-"}]}`,
+		},
+		{
+			name:    "unknown field",
+			policy:  `{"rules": [{"name": "a", "kind": "min-groups", "group": 1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			policy:  `{"rules": [{"kind": "min-groups", "groups": 1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			policy:  `{"rules": [{"name": "a", "kind": "max-groups", "groups": 1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing paths",
+			policy:  `{"rules": [{"name": "a", "kind": "min-approvals", "approvals": 2}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing owners",
+			policy:  `{"rules": [{"name": "a", "kind": "required-owners", "paths": ["/a"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid severity",
+			policy:  `{"rules": [{"name": "a", "kind": "min-groups", "groups": 1, "severity": "fatal"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid group pattern",
+			policy:  `{"rules": [{"name": "a", "kind": "min-groups", "groups": 1, "group-pattern": "("}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ReadPolicy(strings.NewReader(tt.policy))
+
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidPolicy)) {
+				t.Errorf("wantErr=%t but got error %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(policyTestFile))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	policy, err := ReadPolicy(strings.NewReader(`{"rules": [
+		{"name": "infra", "kind": "min-approvals", "paths": ["/infra/**", "/docs/**"], "approvals": 2},
+		{"name": "self", "kind": "required-owners", "paths": ["/CODEOWNERS", "/docs/CODEOWNERS"], "owners": ["@SECURITY"]},
+		{"name": "groups", "kind": "min-groups", "groups": 1, "severity": "warning", "description": "ask a team"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to read policy: %v", err)
+	}
+
+	testhelper.DeepEqual(t, policy.Checks()[2], Check{
+		Name:     "policy:groups",
+		Doc:      "ask a team",
+		Severity: SeverityWarning,
+		Enabled:  true,
+	})
+
+	groups := Diagnostic{
+		Check:    "policy:groups",
+		Severity: SeverityWarning,
+		Line:     7,
+		Message:  "section 'Docs' lists 0 groups, but at least 1 are required: ask a team",
+	}
+	unnamed := groups
+	unnamed.Line = 0
+	unnamed.Message = "section '' lists 0 groups, but at least 1 are required: ask a team"
+
+	t.Run("without file system", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := policy.Evaluate(file, nil)
+		if err != nil {
+			t.Fatalf("Failed to evaluate policy: %v", err)
+		}
+
+		testhelper.DeepEqual(t, got, []Diagnostic{
+			{Check: "policy:infra", Severity: SeverityInfo, Message: "/infra/** was not checked, because no files were given"},
+			{Check: "policy:infra", Severity: SeverityInfo, Message: "/docs/** was not checked, because no files were given"},
+			{
+				Check:    "policy:self",
+				Severity: SeverityError,
+				Message:  "/docs/CODEOWNERS must be owned by @SECURITY in a required section",
+			},
+			unnamed,
+			groups,
+		})
+	})
+
+	t.Run("with file system", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"CODEOWNERS":             {Data: []byte(policyTestFile)},
+			"infra/main.tf":          {Data: []byte{}},
+			"infra/modules/vpc.tf":   {Data: []byte{}},
+			"infra/README.md":        {Data: []byte{}},
+			"docs/index.md":          {Data: []byte{}},
+			".git/infra/objects/abc": {Data: []byte{}},
+		}
+
+		got, err := policy.Evaluate(file, fsys)
+		if err != nil {
+			t.Fatalf("Failed to evaluate policy: %v", err)
+		}
+
+		testhelper.DeepEqual(t, got, []Diagnostic{
+			{
+				Check:    "policy:infra",
+				Severity: SeverityError,
+				Message:  "/docs/index.md requires a required section with at least 2 approvals, but at most 1 are required",
+			},
+			unnamed,
+			groups,
+		})
+	})
+}
+
+func TestPolicy_EvaluateGroupOwners(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("* @security\n[Docs] @alice\ndocs/\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name:   "top-level groups are not detected",
+			policy: `{"rules": [{"name": "groups", "kind": "min-groups", "groups": 1}]}`,
+			want:   []string{"section '' lists 0 groups, but at least 1 are required", "section 'Docs' lists 0 groups, but at least 1 are required"},
+		},
+		{
+			name:   "listed group owners",
+			policy: `{"rules": [{"name": "groups", "kind": "min-groups", "groups": 1, "group-owners": ["@Security"]}]}`,
+			want:   []string{"section 'Docs' lists 0 groups, but at least 1 are required"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := ReadPolicy(strings.NewReader(tt.policy))
+			if err != nil {
+				t.Fatalf("Failed to read policy: %v", err)
+			}
+
+			diagnostics, err := policy.Evaluate(file, nil)
+			if err != nil {
+				t.Fatalf("Failed to evaluate policy: %v", err)
+			}
+
+			got := []string{}
+			for _, diagnostic := range diagnostics {
+				got = append(got, diagnostic.Message)
+			}
+
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package gitlabcodeowners
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// QueryOption configures how the paths of a query are matched.
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	normalizePaths  bool
+	caseInsensitive bool
+
+	// now is the time to check the `@expires` annotations against, expired
+	// entries are only ignored if it is set.
+	now           time.Time
+	reportExpired bool
+
+	// sections contains the lowercase names of the sections to query, all
+	// sections are queried if it is nil.
+	sections map[string]bool
+
+	// excluded contains the lowercase owners who can not approve, it is nil
+	// if nobody is excluded.
+	excluded map[string]bool
+
+	// resolver expands groups to the people who can approve for them, it
+	// is nil if every owner is a single person.
+	resolver MembershipResolver
+}
+
+// QueryResult contains the approvals which apply to a single file.
+type QueryResult struct {
+	// Path is the path as it was passed to the query.
+	Path string
+
+	// NormalizedPath is the path which was matched against the rules.
+	NormalizedPath string
+
+	// Approvals maps the name of each section to its required approval.
+	Approvals map[string]Approval
+
+	// Expired maps the name of each section to the approval an expired rule
+	// would have required, it is only set by `WithExpiredOwnersReported`.
+	Expired map[string]Approval
+
+	// Unsatisfiable maps the name of each section which can not be approved,
+	// because fewer people than required approvals are left after the
+	// exclusion, to the approval with the owners before the exclusion. It is
+	// only set by `WithAuthorExcluded` and `WithCommittersExcluded`.
+	Unsatisfiable map[string]Approval
+}
+
+// WithPathNormalization returns an option which normalizes the paths of a
+// query before matching them. A leading `/` is added if it is missing,
+// Windows-style `\` separators are replaced with `/`, duplicated separators
+// are removed and `.` and `..` segments are resolved.
+func WithPathNormalization() QueryOption {
+	return func(config *queryConfig) {
+		config.normalizePaths = true
+	}
+}
+
+// WithCaseInsensitiveMatching returns an option which matches the paths of a
+// query case-insensitively against the patterns of the rules.
+func WithCaseInsensitiveMatching() QueryOption {
+	return func(config *queryConfig) {
+		config.caseInsensitive = true
+	}
+}
+
+// WithSections returns an option which limits a query to the sections with
+// the given names, which are compared case-insensitively. Use an empty name
+// for the rules before the first section header.
+func WithSections(names ...string) QueryOption {
+	return func(config *queryConfig) {
+		if config.sections == nil {
+			config.sections = map[string]bool{}
+		}
+
+		for _, name := range names {
+			config.sections[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// WithAuthorExcluded returns an option which removes the author of a merge
+// request from the owners of the approvals, like Gitlab does if approvals by
+// the author are prevented. Groups are only expanded with `WithOwnerResolver`,
+// otherwise an author who is a member of an owning group is not removed. Use
+// the resulting approvals with `SuggestApprovers` or a `ReviewerPicker`, so
+// the author is never suggested.
+func WithAuthorExcluded(author string) QueryOption {
+	return WithCommittersExcluded(author)
+}
+
+// WithCommittersExcluded returns an option which removes the committers of a
+// merge request from the owners of the approvals, like Gitlab does if
+// approvals by users who add commits are prevented.
+func WithCommittersExcluded(committers ...string) QueryOption {
+	return func(config *queryConfig) {
+		if config.excluded == nil {
+			config.excluded = map[string]bool{}
+		}
+
+		for _, committer := range committers {
+			config.excluded[strings.ToLower(committer)] = true
+		}
+	}
+}
+
+// WithOwnerResolver returns an option which replaces the owners of the
+// approvals by the people who can approve for them, so excluded people are
+// removed from the owning groups too. Without a resolver every owner is
+// treated as a single person.
+func WithOwnerResolver(resolver MembershipResolver) QueryOption {
+	return func(config *queryConfig) {
+		config.resolver = resolver
+	}
+}
+
+// excludeOwners removes the excluded people from the approvals and returns
+// the required approvals which have fewer people left than approvals, it
+// returns nil if nobody is excluded.
+func (c queryConfig) excludeOwners(approvals map[string]Approval) map[string]Approval {
+	if c.resolver != nil {
+		for section, approval := range approvals {
+			approval.Owners = c.people(approval.Owners)
+			approvals[section] = approval
+		}
+	}
+
+	if c.excluded == nil {
+		return nil
+	}
+
+	unsatisfiable := map[string]Approval{}
+
+	for section, approval := range approvals {
+		owners := []string{}
+
+		for _, owner := range approval.Owners {
+			if !c.excluded[strings.ToLower(owner)] {
+				owners = append(owners, owner)
+			}
+		}
+
+		// only report approvals which can not be satisfied because of the
+		// exclusion, the size of groups is unknown without a resolver
+		if len(owners) < len(approval.Owners) && len(owners) < approval.Approvals {
+			unsatisfiable[section] = approval
+		}
+
+		approval.Owners = owners
+		approvals[section] = approval
+	}
+
+	return unsatisfiable
+}
+
+// people returns the people of the owners, where people who are in several
+// groups are only returned once.
+func (c queryConfig) people(owners []string) []string {
+	people := []string{}
+	seen := map[string]bool{}
+
+	for _, owner := range owners {
+		for _, person := range c.resolver.ResolveOwner(owner) {
+			if !seen[strings.ToLower(person)] {
+				seen[strings.ToLower(person)] = true
+				people = append(people, person)
+			}
+		}
+	}
+
+	return people
+}
+
+// includesSection reports if the section with the given name is queried.
+func (c queryConfig) includesSection(name string) bool {
+	return c.sections == nil || c.sections[strings.ToLower(name)]
+}
+
+func newQueryConfig(options []QueryOption) queryConfig {
+	config := queryConfig{
+		normalizePaths:  false,
+		caseInsensitive: false,
+		now:             time.Time{},
+		reportExpired:   false,
+		sections:        nil,
+		excluded:        nil,
+		resolver:        nil,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// normalizePath returns the path which is matched against the rules.
+func (c queryConfig) normalizePath(queryPath string) string {
+	if !c.normalizePaths {
+		return queryPath
+	}
+
+	return path.Clean("/" + strings.ReplaceAll(queryPath, "\\", "/"))
+}
+
+// QueryFile returns the approvals which apply to the file given by its path
+// together with the path which was evaluated after applying the options.
+func (f File) QueryFile(path string, options ...QueryOption) QueryResult {
+	config := newQueryConfig(options)
+	normalizedPath := config.normalizePath(path)
+	approvals, expired := f.requiredApprovals(normalizedPath, config)
+	unsatisfiable := config.excludeOwners(approvals)
+
+	return QueryResult{
+		Path:           path,
+		NormalizedPath: normalizedPath,
+		Approvals:      approvals,
+		Expired:        expired,
+		Unsatisfiable:  unsatisfiable,
+	}
+}
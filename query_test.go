@@ -0,0 +1,244 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestQuery_QueryFile(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]Approval{
+		"": {Pattern: "/docs/*.md", Approvals: 1, Owners: []string{"@docs"}},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		options        []QueryOption
+		wantNormalized string
+		want           map[string]Approval
+	}{
+		{
+			name:           "exact path without options",
+			path:           "/docs/a.md",
+			options:        nil,
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "relative path without options",
+			path:           "docs/a.md",
+			options:        nil,
+			wantNormalized: "docs/a.md",
+			want:           map[string]Approval{},
+		},
+		{
+			name:           "relative path",
+			path:           "docs/a.md",
+			options:        []QueryOption{WithPathNormalization()},
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "path with leading dot segment",
+			path:           "./docs/a.md",
+			options:        []QueryOption{WithPathNormalization()},
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "path with duplicated separators",
+			path:           "/docs//a.md",
+			options:        []QueryOption{WithPathNormalization()},
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "windows path",
+			path:           "docs\\a.md",
+			options:        []QueryOption{WithPathNormalization()},
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "path with parent segments",
+			path:           "src/../docs/./a.md",
+			options:        []QueryOption{WithPathNormalization()},
+			wantNormalized: "/docs/a.md",
+			want:           docs,
+		},
+		{
+			name:           "different case",
+			path:           "/DOCS/A.MD",
+			options:        nil,
+			wantNormalized: "/DOCS/A.MD",
+			want:           map[string]Approval{},
+		},
+		{
+			name:           "different case and case-insensitive matching",
+			path:           "/DOCS/A.MD",
+			options:        []QueryOption{WithCaseInsensitiveMatching()},
+			wantNormalized: "/DOCS/A.MD",
+			want:           docs,
+		},
+		{
+			name:           "all options",
+			path:           "Docs\\A.md",
+			options:        []QueryOption{WithCaseInsensitiveMatching(), WithPathNormalization()},
+			wantNormalized: "/Docs/A.md",
+			want:           docs,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader("/docs/*.md @docs"))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			got := file.QueryFile(tt.path, tt.options...)
+			testhelper.DeepEqual(t, got, QueryResult{
+				Path:           tt.path,
+				NormalizedPath: tt.wantNormalized,
+				Approvals:      tt.want,
+			})
+		})
+	}
+}
+
+func TestQuery_WithSections(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("*.md @all\n[Docs]\n*.md @docs\n[Code]\n*.md @code\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	got := file.GetRequiredApprovalsForFiles([]string{"/README.md"}, WithSections("docs", ""), WithSections("Unknown"))
+	testhelper.DeepEqual(t, got, map[string][]Approval{
+		"":     {{Pattern: "*.md", Approvals: 1, Owners: []string{"@all"}}},
+		"Docs": {{Pattern: "*.md", Approvals: 1, Owners: []string{"@docs"}}},
+	})
+
+	if got := file.GetRequiredApprovalsForFile("/README.md", WithSections()); len(got) != 0 {
+		t.Errorf("expected no approvals without sections, got %v", got)
+	}
+}
+
+func TestQuery_excludedOwners(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(
+		"* @alice\n[Docs] @alice @bob\ndocs/\n[Code][2]\n*.go @Alice @carol\n^[Optional]\n* @carol\n",
+	))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	got := file.QueryFile("/docs/main.go", WithAuthorExcluded("@ALICE"), WithCommittersExcluded("@carol"))
+	testhelper.DeepEqual(t, got, QueryResult{
+		Path:           "/docs/main.go",
+		NormalizedPath: "/docs/main.go",
+		Approvals: map[string]Approval{
+			"":         {Pattern: "*", Approvals: 1, Owners: []string{}},
+			"Docs":     {Pattern: "docs/", Approvals: 1, Owners: []string{"@bob"}},
+			"Code":     {Pattern: "*.go", Approvals: 2, Owners: []string{}},
+			"Optional": {Pattern: "*", Approvals: 0, Owners: []string{}},
+		},
+		Unsatisfiable: map[string]Approval{
+			"":     {Pattern: "*", Approvals: 1, Owners: []string{"@alice"}},
+			"Code": {Pattern: "*.go", Approvals: 2, Owners: []string{"@Alice", "@carol"}},
+		},
+	})
+
+	testhelper.DeepEqual(t, file.GetUnsatisfiableApprovalsForFiles(
+		[]string{"/main.go", "/cmd/main.go", "/docs/README.md"}, WithCommittersExcluded("@alice", "@carol"),
+	), map[string][]Approval{
+		"":     {{Pattern: "*", Approvals: 1, Owners: []string{"@alice"}}},
+		"Code": {{Pattern: "*.go", Approvals: 2, Owners: []string{"@Alice", "@carol"}}},
+	})
+
+	if got := file.QueryFile("/main.go").Unsatisfiable; got != nil {
+		t.Errorf("expected no unsatisfiable approvals without exclusions, got %v", got)
+	}
+}
+
+func TestQuery_excludedPeople(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader("[Sec][2] @a @b\n*.go\n[Docs]\ndocs/ @org/docs\n"))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	got := file.QueryFile("/main.go", WithAuthorExcluded("@a"))
+	testhelper.DeepEqual(t, got.Unsatisfiable, map[string]Approval{
+		"Sec": {Pattern: "*.go", Approvals: 2, Owners: []string{"@a", "@b"}},
+	})
+
+	membership := Membership{Users: nil, Groups: map[string][]string{"@org/docs": {"@a", "@org/writers"}, "@org/writers": {"@A", "@c"}}}
+	paths := []string{"/docs/main.go"}
+
+	approvals := file.GetRequiredApprovalsForFiles(paths, WithAuthorExcluded("@a"), WithOwnerResolver(membership))
+	testhelper.DeepEqual(t, approvals, map[string][]Approval{
+		"Sec":  {{Pattern: "*.go", Approvals: 2, Owners: []string{"@b"}}},
+		"Docs": {{Pattern: "docs/", Approvals: 1, Owners: []string{"@c"}}},
+	})
+
+	// the approvals of the query do not contain the author anymore
+	suggestion := SuggestApprovers(approvals, WithMembershipResolver(membership))
+	testhelper.DeepEqual(t, suggestion.Approvers, []string{"@b", "@c"})
+
+	unsatisfiable := file.GetUnsatisfiableApprovalsForFiles(
+		[]string{"/docs/README.md"}, WithCommittersExcluded("@a", "@c"), WithOwnerResolver(membership),
+	)
+	testhelper.DeepEqual(t, unsatisfiable, map[string][]Approval{
+		"Docs": {{Pattern: "docs/", Approvals: 1, Owners: []string{"@a", "@c"}}},
+	})
+}
+
+func TestQuery_caseInsensitiveRegex(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(".*\\\\.GO @go"), WithDialect(DialectGitea))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	if got := file.GetRequiredApprovalsForFile("/main.go"); len(got) != 0 {
+		t.Errorf("expected no approvals, got %v", got)
+	}
+
+	if got := file.GetRequiredApprovalsForFile("/main.go", WithCaseInsensitiveMatching()); len(got) != 1 {
+		t.Errorf("expected one approval, got %v", got)
+	}
+}
+
+func TestQuery_fnmatchCaseFold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "cat", path: "CAT", want: true},
+		{pattern: "ca[a-z]", path: "CAT", want: true},
+		{pattern: "ca[^T]", path: "cat", want: false},
+		{pattern: "/Ä/*.md", path: "/ä/x.MD", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := fnmatch(tt.pattern, tt.path, true); got != tt.want {
+			t.Errorf("pattern %s, path %s -> got %t, wanted %t", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
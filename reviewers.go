@@ -0,0 +1,311 @@
+package gitlabcodeowners
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownReviewerStrategy is returned for a strategy which does not exist.
+	ErrUnknownReviewerStrategy = errors.New("unknown reviewer strategy")
+
+	// ErrInvalidReviewerState is returned if the reviewer state can not be read.
+	ErrInvalidReviewerState = errors.New("invalid reviewer state")
+)
+
+// ReviewerStrategy describes how a `ReviewerPicker` chooses between the
+// eligible owners.
+type ReviewerStrategy string
+
+const (
+	// ReviewerRoundRobin rotates through the eligible owners of each rule.
+	ReviewerRoundRobin ReviewerStrategy = "round-robin"
+
+	// ReviewerLeastRecentlyAssigned chooses the owners who were assigned
+	// longest ago or never.
+	ReviewerLeastRecentlyAssigned ReviewerStrategy = "least-recently-assigned"
+
+	// ReviewerRandom chooses random owners, which are reproducible with the
+	// seed set by `WithSeed`. Without a seed the sequence of the state is
+	// used, so the owners differ between the picks recorded in the state.
+	ReviewerRandom ReviewerStrategy = "random"
+)
+
+// ReviewerState contains the previous assignments of a `ReviewerPicker`, so
+// it can be stored in a file between the runs of the picker.
+type ReviewerState struct {
+	// Sequence is the number of the last assignment.
+	Sequence int `json:"sequence"`
+
+	// LastAssigned maps each lowercase reviewer to the number of the
+	// assignment they were last chosen in.
+	LastAssigned map[string]int `json:"last-assigned"`
+
+	// RoundRobin maps each rule to the position of the next reviewer.
+	RoundRobin map[string]int `json:"round-robin"`
+}
+
+// NewReviewerState returns the state of a picker which never assigned anyone.
+func NewReviewerState() *ReviewerState {
+	return &ReviewerState{Sequence: 0, LastAssigned: map[string]int{}, RoundRobin: map[string]int{}}
+}
+
+// ReadReviewerState reads a reviewer state in the JSON format. A state with a
+// negative sequence or position is invalid.
+func ReadReviewerState(reader io.Reader) (*ReviewerState, error) {
+	state := NewReviewerState()
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(state); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReviewerState, err)
+	}
+
+	if state.LastAssigned == nil {
+		state.LastAssigned = map[string]int{}
+	}
+
+	if state.RoundRobin == nil {
+		state.RoundRobin = map[string]int{}
+	}
+
+	if state.Sequence < 0 {
+		return nil, fmt.Errorf("%w: negative sequence %d", ErrInvalidReviewerState, state.Sequence)
+	}
+
+	for key, position := range state.RoundRobin {
+		if position < 0 {
+			return nil, fmt.Errorf("%w: negative round-robin position %d of '%s'", ErrInvalidReviewerState, position, key)
+		}
+	}
+
+	return state, nil
+}
+
+// WriteTo writes the reviewer state in the JSON format.
+func (s *ReviewerState) WriteTo(writer io.Writer) (int64, error) {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode reviewer state: %w", err)
+	}
+
+	written, err := writer.Write(append(content, '\n'))
+	if err != nil {
+		return int64(written), fmt.Errorf("failed to write reviewer state: %w", err)
+	}
+
+	return int64(written), nil
+}
+
+// ReviewerOption configures a `ReviewerPicker`.
+type ReviewerOption func(*ReviewerPicker)
+
+// WithUnavailable returns an option which never chooses the given people,
+// for example because they are on vacation.
+func WithUnavailable(people ...string) ReviewerOption {
+	return func(picker *ReviewerPicker) {
+		for _, person := range people {
+			picker.excluded[strings.ToLower(person)] = true
+		}
+	}
+}
+
+// WithReviewerResolver returns an option which expands groups to their
+// members. Without a resolver every owner is treated as a single person.
+func WithReviewerResolver(resolver MembershipResolver) ReviewerOption {
+	return func(picker *ReviewerPicker) {
+		picker.resolver = resolver
+	}
+}
+
+// WithSeed returns an option which seeds the random strategy, by default the
+// sequence of the state is used as seed.
+func WithSeed(seed int64) ReviewerOption {
+	return func(picker *ReviewerPicker) {
+		picker.random = rand.New(rand.NewSource(seed)) //nolint:gosec // not used for security
+	}
+}
+
+// ReviewerPicker chooses concrete reviewers from the eligible owners of the
+// required approvals.
+type ReviewerPicker struct {
+	strategy ReviewerStrategy
+	state    *ReviewerState
+	resolver MembershipResolver
+	excluded map[string]bool
+	random   *rand.Rand
+}
+
+// ReviewerAssignment is the result of `ReviewerPicker.Pick`.
+type ReviewerAssignment struct {
+	// Reviewers contains the chosen people in the order they were chosen.
+	Reviewers []string
+
+	// Sections maps the name of each section to the chosen people who can
+	// approve for it.
+	Sections map[string][]string
+
+	// Unsatisfiable contains the names of the sections which have fewer
+	// eligible people than required approvals, sorted by name.
+	Unsatisfiable []string
+}
+
+// NewReviewerPicker returns a picker using the strategy, which reads and
+// updates the state on each pick.
+func NewReviewerPicker(strategy ReviewerStrategy, state *ReviewerState, options ...ReviewerOption) (*ReviewerPicker, error) {
+	switch strategy {
+	case ReviewerRoundRobin, ReviewerLeastRecentlyAssigned, ReviewerRandom:
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownReviewerStrategy, strategy)
+	}
+
+	if state == nil {
+		state = NewReviewerState()
+	}
+
+	picker := &ReviewerPicker{
+		strategy: strategy,
+		state:    state,
+		resolver: nil,
+		excluded: map[string]bool{},
+		random:   nil,
+	}
+
+	for _, option := range options {
+		option(picker)
+	}
+
+	if picker.random == nil {
+		picker.random = rand.New(rand.NewSource(int64(state.Sequence))) //nolint:gosec // not used for security
+	}
+
+	return picker, nil
+}
+
+// Pick chooses reviewers for the approvals, for example as returned by
+// `GetRequiredApprovalsForFiles`, and records them in the state. Use the
+// `WithAuthorExcluded` option of the query to never choose the author. For each
+// approval as many reviewers are chosen as approvals are required, people
+// who were already chosen for another approval are reused first. Approvals
+// of optional sections are ignored.
+func (p *ReviewerPicker) Pick(approvals map[string][]Approval) ReviewerAssignment {
+	assignment := ReviewerAssignment{Reviewers: []string{}, Sections: map[string][]string{}, Unsatisfiable: []string{}}
+
+	sections := make([]string, 0, len(approvals))
+	for section := range approvals {
+		sections = append(sections, section)
+	}
+
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		for _, approval := range approvals[section] {
+			if approval.Approvals < 1 {
+				continue
+			}
+
+			eligible := p.eligible(approval.Owners)
+			if len(eligible) < approval.Approvals && !slices.Contains(assignment.Unsatisfiable, section) {
+				assignment.Unsatisfiable = append(assignment.Unsatisfiable, section)
+			}
+
+			chosen, candidates := []string{}, []string{}
+
+			for _, person := range eligible {
+				if slices.Contains(assignment.Reviewers, person) && len(chosen) < approval.Approvals {
+					chosen = append(chosen, person)
+				} else {
+					candidates = append(candidates, person)
+				}
+			}
+
+			count := min(approval.Approvals-len(chosen), len(candidates))
+			chosen = append(chosen, p.choose(section+" "+approval.Pattern, candidates, count)...)
+
+			for _, person := range chosen {
+				if !slices.Contains(assignment.Reviewers, person) {
+					assignment.Reviewers = append(assignment.Reviewers, person)
+				}
+
+				if !slices.Contains(assignment.Sections[section], person) {
+					assignment.Sections[section] = append(assignment.Sections[section], person)
+				}
+			}
+		}
+	}
+
+	sort.Strings(assignment.Unsatisfiable)
+
+	if len(assignment.Reviewers) > 0 {
+		p.state.Sequence++
+
+		for _, person := range assignment.Reviewers {
+			p.state.LastAssigned[strings.ToLower(person)] = p.state.Sequence
+		}
+	}
+
+	return assignment
+}
+
+// eligible returns the people who can review for the owners without the
+// excluded people.
+func (p *ReviewerPicker) eligible(owners []string) []string {
+	eligible := []string{}
+	seen := map[string]bool{}
+
+	for _, owner := range owners {
+		people := []string{owner}
+		if p.resolver != nil {
+			people = p.resolver.ResolveOwner(owner)
+		}
+
+		for _, person := range people {
+			key := strings.ToLower(person)
+			if !seen[key] && !p.excluded[key] {
+				seen[key] = true
+				eligible = append(eligible, person)
+			}
+		}
+	}
+
+	return eligible
+}
+
+// choose returns the given number of candidates using the strategy, the key
+// identifies the rotation of the round-robin strategy.
+func (p *ReviewerPicker) choose(key string, candidates []string, count int) []string {
+	if count <= 0 {
+		return nil
+	}
+
+	ordered := append([]string{}, candidates...)
+
+	switch p.strategy {
+	case ReviewerRoundRobin:
+		// a position set outside of `ReadReviewerState` may be negative
+		start := p.state.RoundRobin[key] % len(ordered)
+		if start < 0 {
+			start += len(ordered)
+		}
+
+		ordered = append(append([]string{}, ordered[start:]...), ordered[:start]...)
+		p.state.RoundRobin[key] = (start + count) % len(candidates)
+	case ReviewerLeastRecentlyAssigned:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return p.state.LastAssigned[strings.ToLower(ordered[i])] < p.state.LastAssigned[strings.ToLower(ordered[j])]
+		})
+	case ReviewerRandom:
+		p.random.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	}
+
+	return ordered[:count]
+}
@@ -0,0 +1,181 @@
+package gitlabcodeowners
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestReviewers_Pick(t *testing.T) {
+	t.Parallel()
+
+	approvals := map[string][]Approval{
+		"Docs": {{Pattern: "docs/", Approvals: 1, Owners: []string{"@alice", "@bob", "@carol"}}},
+	}
+
+	tests := []struct {
+		name     string
+		strategy ReviewerStrategy
+		state    *ReviewerState
+		options  []ReviewerOption
+		want     [][]string
+	}{
+		{
+			name:     "round-robin",
+			strategy: ReviewerRoundRobin,
+			want:     [][]string{{"@alice"}, {"@bob"}, {"@carol"}, {"@alice"}},
+		},
+		{
+			name:     "round-robin without unavailable people",
+			strategy: ReviewerRoundRobin,
+			options:  []ReviewerOption{WithUnavailable("@BOB", "@dave")},
+			want:     [][]string{{"@alice"}, {"@carol"}, {"@alice"}},
+		},
+		{
+			name:     "least recently assigned",
+			strategy: ReviewerLeastRecentlyAssigned,
+			state: &ReviewerState{
+				Sequence:     5,
+				LastAssigned: map[string]int{"@alice": 5, "@carol": 3},
+				RoundRobin:   map[string]int{},
+			},
+			want: [][]string{{"@bob"}, {"@carol"}, {"@alice"}, {"@bob"}},
+		},
+		{
+			name:     "least recently assigned without unavailable people",
+			strategy: ReviewerLeastRecentlyAssigned,
+			options:  []ReviewerOption{WithUnavailable("@alice", "@carol")},
+			want:     [][]string{{"@bob"}, {"@bob"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			picker, err := NewReviewerPicker(tt.strategy, tt.state, tt.options...)
+			if err != nil {
+				t.Fatalf("Failed to create picker: %v", err)
+			}
+
+			got := [][]string{}
+			for range tt.want {
+				got = append(got, picker.Pick(approvals).Reviewers)
+			}
+
+			testhelper.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestReviewers_Pick_sections(t *testing.T) {
+	t.Parallel()
+
+	picker, err := NewReviewerPicker(ReviewerLeastRecentlyAssigned, nil,
+		WithUnavailable("@carol"),
+		WithReviewerResolver(Membership{Users: nil, Groups: map[string][]string{"@org/docs": {"@alice", "@bob"}}}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create picker: %v", err)
+	}
+
+	got := picker.Pick(map[string][]Approval{
+		"Code":     {{Pattern: "*.go", Approvals: 1, Owners: []string{"@bob", "@dave"}}},
+		"Docs":     {{Pattern: "docs/", Approvals: 2, Owners: []string{"@org/docs", "@erin"}}},
+		"Optional": {{Pattern: "*", Approvals: 0, Owners: []string{"@frank"}}},
+		"Security": {{Pattern: "*", Approvals: 2, Owners: []string{"@carol", "@dave"}}},
+	})
+
+	testhelper.DeepEqual(t, got, ReviewerAssignment{
+		Reviewers:     []string{"@bob", "@alice", "@dave"},
+		Sections:      map[string][]string{"Code": {"@bob"}, "Docs": {"@bob", "@alice"}, "Security": {"@dave"}},
+		Unsatisfiable: []string{"Security"},
+	})
+}
+
+func TestReviewers_Pick_random(t *testing.T) {
+	t.Parallel()
+
+	approvals := map[string][]Approval{
+		"Docs": {{Pattern: "docs/", Approvals: 2, Owners: []string{"@a", "@b", "@c", "@d", "@e"}}},
+	}
+
+	pick := func(seed int64) [][]string {
+		picker, err := NewReviewerPicker(ReviewerRandom, nil, WithSeed(seed))
+		if err != nil {
+			t.Fatalf("Failed to create picker: %v", err)
+		}
+
+		picks := [][]string{}
+		for i := 0; i < 5; i++ {
+			picks = append(picks, picker.Pick(approvals).Reviewers)
+		}
+
+		return picks
+	}
+
+	first := pick(42)
+	testhelper.DeepEqual(t, pick(42), first)
+
+	for _, reviewers := range first {
+		if len(reviewers) != 2 || reviewers[0] == reviewers[1] {
+			t.Errorf("got reviewers %v, wanted two different people", reviewers)
+		}
+	}
+
+	// without a seed the sequence of the state is used
+	state := NewReviewerState()
+	state.Sequence = 42
+
+	picker, err := NewReviewerPicker(ReviewerRandom, state)
+	if err != nil {
+		t.Fatalf("Failed to create picker: %v", err)
+	}
+
+	testhelper.DeepEqual(t, picker.Pick(approvals).Reviewers, first[0])
+}
+
+func TestReviewers_ReviewerState(t *testing.T) {
+	t.Parallel()
+
+	picker, err := NewReviewerPicker(ReviewerRoundRobin, nil)
+	if err != nil {
+		t.Fatalf("Failed to create picker: %v", err)
+	}
+
+	picker.Pick(map[string][]Approval{"Docs": {{Pattern: "docs/", Approvals: 1, Owners: []string{"@Alice", "@bob"}}}})
+
+	buffer := bytes.Buffer{}
+	if _, err := picker.state.WriteTo(&buffer); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+
+	state, err := ReadReviewerState(&buffer)
+	if err != nil {
+		t.Fatalf("Failed to read state: %v", err)
+	}
+
+	testhelper.DeepEqual(t, state, &ReviewerState{
+		Sequence:     1,
+		LastAssigned: map[string]int{"@alice": 1},
+		RoundRobin:   map[string]int{"Docs docs/": 1},
+	})
+
+	for _, invalid := range []string{`{"next": 1}`, `{"sequence": -1}`, `{"round-robin": {"Docs docs/": -3}}`} {
+		if _, err := ReadReviewerState(bytes.NewBufferString(invalid)); !errors.Is(err, ErrInvalidReviewerState) {
+			t.Errorf("got error %v for %s, wanted %v", err, invalid, ErrInvalidReviewerState)
+		}
+	}
+
+	// a negative position set in code does not panic
+	picker.state.RoundRobin["Docs docs/"] = -3
+	picker.Pick(map[string][]Approval{"Docs": {{Pattern: "docs/", Approvals: 1, Owners: []string{"@Alice", "@bob"}}}})
+
+	if _, err := NewReviewerPicker("first", nil); !errors.Is(err, ErrUnknownReviewerStrategy) {
+		t.Errorf("got error %v, wanted %v", err, ErrUnknownReviewerStrategy)
+	}
+}
@@ -5,21 +5,40 @@ import (
 )
 
 type rule struct {
-	pattern pattern
-	owners  []string
+	pattern    pattern
+	owners     []string
+	selections []ReviewerSelection
+	comment    string
+
+	// aliasedOwners contains the owners as they are written with aliases,
+	// it is nil if the owners do not use an alias.
+	aliasedOwners []string
+
+	src source
 }
 
-func parseRule(line string) rule {
+func parseRule(line string) (rule, error) {
 	parts := strings.Fields(line)
 
 	if len(parts) == 0 {
-		// This should never happen because empty lines are ignored
-		// during the parsing of the `CODEOWNERS` file.
-		panic("Parsing an empty line as a rule is not possible, this should not happen!")
+		return rule{}, ErrEmptyRule
+	}
+
+	pattern, err := parsePattern(parts[0])
+	if err != nil {
+		return rule{}, err
 	}
 
+	return newRule(pattern, parts[1:]), nil
+}
+
+func newRule(pattern pattern, owners []string) rule {
 	return rule{
-		pattern: newPattern(parts[0]),
-		owners:  parts[1:],
+		pattern:       pattern,
+		owners:        owners,
+		selections:    nil,
+		comment:       "",
+		aliasedOwners: nil,
+		src:           source{line: 0, raw: "", leading: nil},
 	}
 }
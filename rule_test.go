@@ -1,6 +1,7 @@
 package gitlabcodeowners
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/chefe/gitlabcodeowners/testhelper"
@@ -18,7 +19,7 @@ func TestRule_parseRule(t *testing.T) {
 			name: "single owner",
 			rule: "/*.md @username",
 			want: rule{
-				pattern: pattern{value: "/*.md", normalized: "/*.md"},
+				pattern: wantPattern("/*.md", "/*.md"),
 				owners:  []string{"@username"},
 			},
 		},
@@ -26,7 +27,7 @@ func TestRule_parseRule(t *testing.T) {
 			name: "multiple owners",
 			rule: "/path/to/entry.txt @group @group/subgroup @user",
 			want: rule{
-				pattern: pattern{value: "/path/to/entry.txt", normalized: "/path/to/entry.txt"},
+				pattern: wantPattern("/path/to/entry.txt", "/path/to/entry.txt"),
 				owners:  []string{"@group", "@group/subgroup", "@user"},
 			},
 		},
@@ -34,7 +35,7 @@ func TestRule_parseRule(t *testing.T) {
 			name: "multiple owners with tabs",
 			rule: "/path/to/entry.txt\t@username\tjanedoe@gitlab.com",
 			want: rule{
-				pattern: pattern{value: "/path/to/entry.txt", normalized: "/path/to/entry.txt"},
+				pattern: wantPattern("/path/to/entry.txt", "/path/to/entry.txt"),
 				owners:  []string{"@username", "janedoe@gitlab.com"},
 			},
 		},
@@ -42,7 +43,7 @@ func TestRule_parseRule(t *testing.T) {
 			name: "entries with spaces",
 			rule: "folder with spaces/*.md @group",
 			want: rule{
-				pattern: pattern{value: "folder", normalized: "/**/folder"},
+				pattern: wantPattern("folder", "/**/folder"),
 				owners:  []string{"with", "spaces/*.md", "@group"},
 			},
 		},
@@ -50,7 +51,7 @@ func TestRule_parseRule(t *testing.T) {
 			name: "no owner",
 			rule: "/file.md",
 			want: rule{
-				pattern: pattern{value: "/file.md", normalized: "/file.md"},
+				pattern: wantPattern("/file.md", "/file.md"),
 				owners:  []string{},
 			},
 		},
@@ -62,8 +63,22 @@ func TestRule_parseRule(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := parseRule(tt.rule)
+			got, err := parseRule(tt.rule)
+			if err != nil {
+				t.Errorf("Failed to parse rule: %v", err)
+			}
+
 			testhelper.DeepEqual(t, got, tt.want)
 		})
 	}
 }
+
+func TestRule_parseRuleEmpty(t *testing.T) {
+	t.Parallel()
+
+	for _, line := range []string{"", " ", "\t"} {
+		if _, err := parseRule(line); !errors.Is(err, ErrEmptyRule) {
+			t.Errorf("got error %v for line %q, wanted %v", err, line, ErrEmptyRule)
+		}
+	}
+}
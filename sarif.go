@@ -0,0 +1,223 @@
+package gitlabcodeowners
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifToolURI = "https://github.com/chefe/gitlabcodeowners"
+)
+
+// SARIFReport collects diagnostics of several files to write them as a
+// SARIF 2.1.0 log, which can be read by most code scanning dashboards. The
+// names of the checks are used as stable rule IDs.
+type SARIFReport struct {
+	rules   []sarifRule
+	indexes map[string]int
+	results []sarifResult
+}
+
+// NewSARIFReport returns an empty report which describes the given checks
+// as the rules of the analyzer.
+func NewSARIFReport(checks ...Check) *SARIFReport {
+	report := &SARIFReport{rules: []sarifRule{}, indexes: map[string]int{}, results: []sarifResult{}}
+
+	for _, check := range checks {
+		report.addRule(check.Name, check.Doc, check.Severity)
+	}
+
+	return report
+}
+
+// Add adds the diagnostics found in the file at the given path. The path is
+// relative to the root of the repository like in `CodeQualityIssues`.
+func (r *SARIFReport) Add(path string, diagnostics []Diagnostic) {
+	uri := reportPath(path)
+
+	for _, diagnostic := range diagnostics {
+		index, ok := r.indexes[diagnostic.Check]
+		if !ok {
+			index = r.addRule(diagnostic.Check, "", diagnostic.Severity)
+		}
+
+		result := sarifResult{
+			RuleID:    diagnostic.Check,
+			RuleIndex: index,
+			Level:     sarifLevel(diagnostic.Severity),
+			Message:   sarifMessage{Text: diagnostic.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+				Region:           nil,
+			}}},
+			Fixes: nil,
+		}
+
+		if diagnostic.Line > 0 {
+			result.Locations[0].PhysicalLocation.Region = &sarifRegion{
+				StartLine:   diagnostic.Line,
+				StartColumn: diagnostic.Column,
+				EndLine:     0,
+				EndColumn:   0,
+			}
+		}
+
+		if diagnostic.Fix != nil {
+			result.Fixes = []sarifFix{newSARIFFix(uri, *diagnostic.Fix)}
+		}
+
+		r.results = append(r.results, result)
+	}
+}
+
+// Write writes the report as a SARIF log with a single run.
+func (r *SARIFReport) Write(writer io.Writer) error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gitlabcodeowners",
+				InformationURI: sarifToolURI,
+				Rules:          r.rules,
+			}},
+			Results: r.results,
+		}},
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SARIFReport) addRule(id, doc string, severity Severity) int {
+	if index, ok := r.indexes[id]; ok {
+		return index
+	}
+
+	rule := sarifRule{ID: id, ShortDescription: nil, DefaultConfiguration: sarifConfiguration{Level: sarifLevel(severity)}}
+	if doc != "" {
+		rule.ShortDescription = &sarifMessage{Text: doc}
+	}
+
+	r.rules = append(r.rules, rule)
+	r.indexes[id] = len(r.rules) - 1
+
+	return len(r.rules) - 1
+}
+
+func newSARIFFix(uri string, fix Fix) sarifFix {
+	// a fix without a column replaces the whole line including its line break
+	region := sarifRegion{StartLine: fix.Line, StartColumn: 1, EndLine: fix.Line + 1, EndColumn: 1}
+	if fix.Column > 0 {
+		region = sarifRegion{StartLine: fix.Line, StartColumn: fix.Column, EndLine: fix.Line, EndColumn: fix.EndColumn}
+	}
+
+	replacement := sarifReplacement{DeletedRegion: region, InsertedContent: nil}
+	if fix.Text != "" {
+		replacement.InsertedContent = &sarifMessage{Text: fix.Text}
+	}
+
+	return sarifFix{
+		Description: sarifMessage{Text: fix.Description},
+		ArtifactChanges: []sarifArtifactChange{{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+			Replacements:     []sarifReplacement{replacement},
+		}},
+	}
+}
+
+func sarifLevel(severity Severity) string {
+	if severity == SeverityInfo {
+		return "note"
+	}
+
+	return string(severity)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     *sarifMessage      `json:"shortDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+}
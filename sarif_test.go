@@ -0,0 +1,199 @@
+package gitlabcodeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chefe/gitlabcodeowners/testhelper"
+)
+
+func TestSARIF_Write(t *testing.T) {
+	t.Parallel()
+
+	report := NewSARIFReport(
+		Check{Name: "invalid-owner", Doc: "reports invalid owners", Severity: SeverityError, Enabled: true},
+		Check{Name: "unsorted-rules", Severity: SeverityInfo},
+	)
+
+	report.Add("/CODEOWNERS", []Diagnostic{
+		{
+			Check:    "invalid-owner",
+			Severity: SeverityError,
+			Line:     2,
+			Column:   6,
+			Message:  "owner 'alice' is invalid",
+			Fix:      &Fix{Description: "prefix 'alice' with '@'", Line: 2, Column: 6, EndColumn: 11, Text: "@alice"},
+		},
+		{
+			Check:    "duplicate-pattern",
+			Severity: SeverityWarning,
+			Line:     5,
+			Message:  "duplicate",
+			Fix:      &Fix{Description: "remove the rule on line 3", Line: 3},
+		},
+	})
+	report.Add("docs/CODEOWNERS", []Diagnostic{
+		{Check: "policy:groups", Severity: SeverityInfo, Message: "no groups"},
+	})
+
+	buffer := bytes.Buffer{}
+	if err := report.Write(&buffer); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	testhelper.DeepEqual(t, buffer.String(), `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "gitlabcodeowners",
+          "informationUri": "https://github.com/chefe/gitlabcodeowners",
+          "rules": [
+            {
+              "id": "invalid-owner",
+              "shortDescription": {
+                "text": "reports invalid owners"
+              },
+              "defaultConfiguration": {
+                "level": "error"
+              }
+            },
+            {
+              "id": "unsorted-rules",
+              "defaultConfiguration": {
+                "level": "note"
+              }
+            },
+            {
+              "id": "duplicate-pattern",
+              "defaultConfiguration": {
+                "level": "warning"
+              }
+            },
+            {
+              "id": "policy:groups",
+              "defaultConfiguration": {
+                "level": "note"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "invalid-owner",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "owner 'alice' is invalid"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "CODEOWNERS"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 6
+                }
+              }
+            }
+          ],
+          "fixes": [
+            {
+              "description": {
+                "text": "prefix 'alice' with '@'"
+              },
+              "artifactChanges": [
+                {
+                  "artifactLocation": {
+                    "uri": "CODEOWNERS"
+                  },
+                  "replacements": [
+                    {
+                      "deletedRegion": {
+                        "startLine": 2,
+                        "startColumn": 6,
+                        "endLine": 2,
+                        "endColumn": 11
+                      },
+                      "insertedContent": {
+                        "text": "@alice"
+                      }
+                    }
+                  ]
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "duplicate-pattern",
+          "ruleIndex": 2,
+          "level": "warning",
+          "message": {
+            "text": "duplicate"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "CODEOWNERS"
+                },
+                "region": {
+                  "startLine": 5
+                }
+              }
+            }
+          ],
+          "fixes": [
+            {
+              "description": {
+                "text": "remove the rule on line 3"
+              },
+              "artifactChanges": [
+                {
+                  "artifactLocation": {
+                    "uri": "CODEOWNERS"
+                  },
+                  "replacements": [
+                    {
+                      "deletedRegion": {
+                        "startLine": 3,
+                        "startColumn": 1,
+                        "endLine": 4,
+                        "endColumn": 1
+                      }
+                    }
+                  ]
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "policy:groups",
+          "ruleIndex": 3,
+          "level": "note",
+          "message": {
+            "text": "no groups"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docs/CODEOWNERS"
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`)
+}
@@ -2,33 +2,42 @@ package gitlabcodeowners
 
 import (
 	"errors"
-	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
-const (
-	maxSquareBracketsInHeader      = 2
-	partCountWithApprovalAndOwners = 3
-	partCountWithApprovalOrOwners  = 2
-)
+const maxSquareBracketsInHeader = 2
 
 var (
-	errNoMatchingBracketCount = errors.New("no matching bracket count")
-	errTooMuchBracketsFound   = errors.New("too much brackets found")
+	// sectionHeaderRegex matches the name, the optional approval count and the
+	// default owners of a section header without the optional indicator.
+	sectionHeaderRegex = regexp.MustCompile(`^\[([^\[\]]*)\](?:\[([^\[\]]*)\])?([^\[\]]*)$`)
+
+	// sectionHeaderPrefixRegex matches the well-formed brackets at the
+	// beginning of a section header without the optional indicator.
+	sectionHeaderPrefixRegex = regexp.MustCompile(`^\[[^\[\]]*\](?:\[[^\[\]]*\])?`)
 )
 
 type section struct {
 	name      string
 	approvals int
+	optional  bool
 	owners    []string
 	rules     []rule
+	comment   string
+
+	// aliasedOwners contains the default owners as they are written with
+	// aliases, it is nil if the owners do not use an alias.
+	aliasedOwners []string
+
+	src source
 }
 
 func parseSectionHeader(header string) (section, error) {
 	err := checkBracketCountInSectionHeader(header)
 	if err != nil {
-		return section{}, fmt.Errorf("failed to parse section header '%s': %w", header, err)
+		return section{}, err
 	}
 
 	// remove optional indicator from header
@@ -37,14 +46,43 @@ func parseSectionHeader(header string) (section, error) {
 		header = header[1:]
 	}
 
-	name, approvals, owners := extractPartsFromSectionHeader(header)
+	name, approvals, owners, err := extractPartsFromSectionHeader(header)
+	if err != nil {
+		return section{}, err
+	}
+
+	return newSection(
+		strings.TrimSpace(name),
+		parseApprovalCount(approvals, optional),
+		optional,
+		strings.Fields(owners),
+	), nil
+}
+
+// sectionHeaderEnd returns the byte offset after the brackets of the header
+// in the line, so the brackets in an inline comment are ignored. For a
+// malformed header it is the offset after the last closing bracket.
+func sectionHeaderEnd(line string) int {
+	start := len(line) - len(strings.TrimPrefix(line, "^"))
+
+	if prefix := sectionHeaderPrefixRegex.FindString(line[start:]); prefix != "" {
+		return start + len(prefix)
+	}
+
+	return strings.LastIndex(line, "]") + 1
+}
 
+func newSection(name string, approvals int, optional bool, owners []string) section {
 	return section{
-		name:      strings.TrimSpace(name),
-		approvals: parseApprovalCount(approvals, optional),
-		owners:    strings.Fields(owners),
-		rules:     []rule{},
-	}, nil
+		name:          name,
+		approvals:     approvals,
+		optional:      optional,
+		owners:        owners,
+		rules:         []rule{},
+		comment:       "",
+		aliasedOwners: nil,
+		src:           source{line: 0, raw: "", leading: nil},
+	}
 }
 
 func checkBracketCountInSectionHeader(header string) error {
@@ -52,45 +90,27 @@ func checkBracketCountInSectionHeader(header string) error {
 
 	switch {
 	case count != strings.Count(header, "]"):
-		return errNoMatchingBracketCount
+		return ErrNoMatchingBracketCount
 	case count > maxSquareBracketsInHeader:
-		return errTooMuchBracketsFound
+		return ErrTooMuchBracketsFound
 	case count == 0:
-		// This should never happen because the line is only
-		// parsed as a header if it starts with `^[` or `[`.
-		panic("No square brackets found in section header, this should not happen")
+		return ErrNoBracketsFound
 	}
 
 	return nil
 }
 
-func extractPartsFromSectionHeader(header string) (name, approvals, owners string) { //nolint:nonamedreturns,lll // give the return param strings a name
-	// split header into parts based on square brackets
-	parts := strings.FieldsFunc(header, func(c rune) bool {
-		return c == ']' || c == '['
-	})
-
-	switch {
-	// approval count and default owners
-	case len(parts) == partCountWithApprovalAndOwners:
-		return parts[0], parts[1], parts[2]
-
-	// only approval count but no default owners
-	case len(parts) == partCountWithApprovalOrOwners && strings.Count(header, "[") == 2:
-		return parts[0], parts[1], ""
-
-	// default owners but no approval count
-	case len(parts) == partCountWithApprovalOrOwners:
-		return parts[0], "", parts[1]
+func extractPartsFromSectionHeader(header string) (name, approvals, owners string, err error) { //nolint:nonamedreturns,lll // give the return param strings a name
+	parts := sectionHeaderRegex.FindStringSubmatch(header)
+	if parts == nil {
+		return "", "", "", ErrMalformedSectionHeader
+	}
 
-		// only section name
-	case len(parts) == 1:
-		return parts[0], "", ""
+	if strings.TrimSpace(parts[1]) == "" {
+		return "", "", "", ErrMissingSectionName
 	}
 
-	// This should never happen because amount of square brackets
-	// is checked before this function gets called.
-	panic("Invalid amount of square brackets in section header, this should not happen")
+	return parts[1], parts[2], parts[3], nil
 }
 
 func parseApprovalCount(count string, optional bool) int {
@@ -108,3 +128,139 @@ func parseApprovalCount(count string, optional bool) int {
 
 	return approvals
 }
+
+// sectionHeaderErrorOffset returns the byte offset of the character in the
+// header which caused the given error from `parseSectionHeader`.
+func sectionHeaderErrorOffset(header string, err error) int {
+	switch {
+	case errors.Is(err, ErrTooMuchBracketsFound):
+		offset := 0
+		for i := 0; i <= maxSquareBracketsInHeader; i++ {
+			offset += strings.Index(header[offset:], "[") + 1
+		}
+
+		return offset - 1
+
+	case errors.Is(err, ErrNoMatchingBracketCount):
+		return unmatchedBracketOffset(header)
+
+	case errors.Is(err, ErrMalformedSectionHeader):
+		start := len(header) - len(strings.TrimPrefix(header, "^"))
+		end := start + len(sectionHeaderPrefixRegex.FindString(header[start:]))
+
+		if index := strings.IndexAny(header[end:], "[]"); index >= 0 {
+			return end + index
+		}
+
+		return start
+
+	case errors.Is(err, ErrMissingSectionName):
+		return strings.Index(header, "[") + 1
+	}
+
+	return 0
+}
+
+// unmatchedBracketOffset returns the byte offset of the first closing
+// bracket without an opening bracket or of the last opening bracket
+// without a closing bracket.
+func unmatchedBracketOffset(header string) int {
+	opened := []int{}
+
+	for i, char := range header {
+		switch {
+		case char == '[':
+			opened = append(opened, i)
+		case char == ']' && len(opened) == 0:
+			return i
+		case char == ']':
+			opened = opened[:len(opened)-1]
+		}
+	}
+
+	if len(opened) > 0 {
+		return opened[len(opened)-1]
+	}
+
+	return 0
+}
+
+// Section describes a section of a `CODEOWNERS` file. Sections with the
+// same name are merged case-insensitively like Gitlab does, so the first
+// section header defines the approval count and the default owners.
+type Section struct {
+	// Name is the name of the section, it is empty for the rules before
+	// the first section header.
+	Name string
+
+	// Approvals is the number of required approvals, it is 0 for an
+	// optional section.
+	Approvals int
+
+	Optional bool
+
+	// Owners contains the default owners of the section header.
+	Owners []string
+
+	// Patterns contains the patterns of all rules in the section.
+	Patterns []string
+
+	// Line is the line of the first section header starting at 1, it is
+	// 0 for the rules before the first section header.
+	Line int
+
+	// Comment is the text of the inline comment on the section header.
+	Comment string
+
+	// Doc is the text of the comment block directly above the section header.
+	Doc string
+
+	// Annotations contains the annotations like `@contact` from the comment
+	// block above the section header.
+	Annotations map[string]string
+}
+
+// Sections returns all sections of the file in the order of their first
+// section header.
+func (f File) Sections() []Section {
+	sections := make([]Section, 0, len(f.sections))
+
+	for _, sec := range f.sections {
+		sections = append(sections, newSectionInfo(sec))
+	}
+
+	return sections
+}
+
+// Section returns the section with the given name, which is compared
+// case-insensitively. The boolean is false if there is no such section.
+func (f File) Section(name string) (Section, bool) {
+	index := f.findSection(name)
+	if index < 0 {
+		return Section{}, false //nolint:exhaustruct // not used if not found
+	}
+
+	return newSectionInfo(f.sections[index]), true
+}
+
+func newSectionInfo(sec section) Section {
+	patterns := make([]string, 0, len(sec.rules))
+
+	for _, r := range sec.rules {
+		patterns = append(patterns, r.pattern.value)
+	}
+
+	doc, annotations := sec.src.docComment()
+
+	return Section{
+		Name:        sec.name,
+		Approvals:   sec.approvals,
+		Optional:    sec.optional,
+		Owners:      append([]string{}, sec.owners...),
+		Patterns:    patterns,
+		Line:        sec.src.line,
+		Comment:     sec.comment,
+		Doc:         doc,
+		Annotations: annotations,
+	}
+}
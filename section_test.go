@@ -1,6 +1,8 @@
 package gitlabcodeowners
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/chefe/gitlabcodeowners/testhelper"
@@ -32,6 +34,7 @@ func TestSection_parseSectionHeader(t *testing.T) {
 			want: section{
 				name:      "Section name",
 				approvals: 0,
+				optional:  true,
 				owners:    []string{},
 				rules:     []rule{},
 			},
@@ -54,6 +57,7 @@ func TestSection_parseSectionHeader(t *testing.T) {
 			want: section{
 				name:      "Section name",
 				approvals: 0,
+				optional:  true,
 				owners:    []string{},
 				rules:     []rule{},
 			},
@@ -76,6 +80,7 @@ func TestSection_parseSectionHeader(t *testing.T) {
 			want: section{
 				name:      "Section name",
 				approvals: 0,
+				optional:  true,
 				owners:    []string{"@username"},
 				rules:     []rule{},
 			},
@@ -98,6 +103,7 @@ func TestSection_parseSectionHeader(t *testing.T) {
 			want: section{
 				name:      "Docs",
 				approvals: 0,
+				optional:  true,
 				owners:    []string{"@group", "@subgroup"},
 				rules:     []rule{},
 			},
@@ -200,6 +206,11 @@ func TestSection_checkBracketCountInSectionHeader(t *testing.T) {
 			header:  "[Documentation @docs-team",
 			wantErr: true,
 		},
+		{
+			name:    "00 - no brackets",
+			header:  "Documentation @docs-team",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -227,9 +238,10 @@ func TestSection_extractPartsFromSectionHeader(t *testing.T) {
 	}
 
 	tests := []struct {
-		name   string
-		header string
-		want   parts
+		name    string
+		header  string
+		want    parts
+		wantErr error
 	}{
 		{
 			name:   "name with approval count and default owners",
@@ -267,6 +279,30 @@ func TestSection_extractPartsFromSectionHeader(t *testing.T) {
 				owners:    "",
 			},
 		},
+		{
+			name:    "closing bracket before opening bracket",
+			header:  "[a]]b[",
+			want:    parts{name: "", approvals: "", owners: ""},
+			wantErr: ErrMalformedSectionHeader,
+		},
+		{
+			name:    "brackets in default owners",
+			header:  "[Documentation] @docs [2]",
+			want:    parts{name: "", approvals: "", owners: ""},
+			wantErr: ErrMalformedSectionHeader,
+		},
+		{
+			name:    "empty name",
+			header:  "[][4]",
+			want:    parts{name: "", approvals: "", owners: ""},
+			wantErr: ErrMissingSectionName,
+		},
+		{
+			name:    "blank name",
+			header:  "[  ]",
+			want:    parts{name: "", approvals: "", owners: ""},
+			wantErr: ErrMissingSectionName,
+		},
 	}
 
 	for _, tt := range tests {
@@ -275,9 +311,13 @@ func TestSection_extractPartsFromSectionHeader(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			name, approvals, owners := extractPartsFromSectionHeader(tt.header)
+			name, approvals, owners, err := extractPartsFromSectionHeader(tt.header)
 			got := parts{name: name, approvals: approvals, owners: owners}
 			testhelper.DeepEqual(t, got, tt.want)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, wanted %v", err, tt.wantErr)
+			}
 		})
 	}
 }
@@ -379,3 +419,43 @@ func TestSection_parseApprovalCount(t *testing.T) {
 		})
 	}
 }
+
+func TestSection_Sections(t *testing.T) {
+	t.Parallel()
+
+	file, err := NewCodeOwnersFile(strings.NewReader(
+		"* @all\n\n# Documentation\n# @contact #docs\n[Docs][2] @docs # writers\ndocs/\n\n" +
+			"^[Database] @db\nmodel/db/\n\n[DOCS]\n*.md @alice\n",
+	))
+	if err != nil {
+		t.Fatalf("Failed to create code owners file: %v", err)
+	}
+
+	docs := Section{
+		Name:        "Docs",
+		Approvals:   2,
+		Owners:      []string{"@docs"},
+		Patterns:    []string{"docs/", "*.md"},
+		Line:        5,
+		Comment:     "writers",
+		Doc:         "Documentation",
+		Annotations: map[string]string{"contact": "#docs"},
+	}
+
+	testhelper.DeepEqual(t, file.Sections(), []Section{
+		{Name: "", Approvals: 1, Owners: []string{}, Patterns: []string{"*"}},
+		docs,
+		{Name: "Database", Approvals: 0, Optional: true, Owners: []string{"@db"}, Patterns: []string{"model/db/"}, Line: 8},
+	})
+
+	got, ok := file.Section("docs")
+	if !ok {
+		t.Fatalf("Section 'docs' not found")
+	}
+
+	testhelper.DeepEqual(t, got, docs)
+
+	if _, ok := file.Section("Unknown"); ok {
+		t.Errorf("found unknown section")
+	}
+}
@@ -0,0 +1,217 @@
+package gitlabcodeowners
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteTo writes the file in the Gitlab syntax to the given writer. Lines
+// which were parsed and not modified afterwards are written exactly as they
+// were read, including the comments and empty lines around them and their
+// line ending. Other lines end like the first line of the parsed file. A
+// file read as UTF-16 is written as UTF-8, because Gitlab expects UTF-8.
+func (f File) WriteTo(writer io.Writer) (int64, error) {
+	builder := strings.Builder{}
+
+	if f.byteOrderMark {
+		builder.Write(utf8ByteOrderMark)
+	}
+
+	lineEnding := "\n"
+	if f.crlf {
+		lineEnding = "\r\n"
+	}
+
+	for _, item := range f.writerItems() {
+		writeLines(&builder, item.lines, lineEnding)
+	}
+
+	writeLines(&builder, f.trailing, lineEnding)
+
+	written, err := io.WriteString(writer, builder.String())
+	if err != nil {
+		return int64(written), fmt.Errorf("failed to write code owners file: %w", err)
+	}
+
+	return int64(written), nil
+}
+
+// writerItem contains the lines of a section header or a rule and the
+// position where they should be written.
+type writerItem struct {
+	position int
+	lines    []string
+}
+
+// writerItems returns the lines of all section headers and rules in the
+// order of the original file. Rules of sections with duplicate names are
+// merged into the first section, so they have to be sorted back to their
+// original line. New section headers and rules are written after the item
+// which precedes them in their section.
+func (f File) writerItems() []writerItem {
+	items := []writerItem{}
+	position := 0
+
+	add := func(src source, line string) {
+		if src.line > 0 {
+			position = src.line
+		}
+
+		items = append(items, writerItem{
+			position: position,
+			lines:    append(append([]string{}, src.leading...), line),
+		})
+	}
+
+	for _, sec := range f.sections {
+		if sec.name != "" {
+			// separate sections which were not read from a file
+			if sec.src.line == 0 && len(items) > 0 {
+				sec.src.leading = append([]string{""}, sec.src.leading...)
+			}
+
+			add(sec.src, sectionHeaderLine(sec))
+		} else if len(sec.src.leading) > 0 {
+			items = append(items, writerItem{position: position, lines: sec.src.leading})
+		}
+
+		for _, r := range sec.rules {
+			add(r.src, ruleLine(r))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].position < items[j].position
+	})
+
+	return items
+}
+
+// String returns the file in the Gitlab syntax as it is written by `WriteTo`.
+func (f File) String() string {
+	builder := strings.Builder{}
+
+	// writing into a strings.Builder never fails
+	_, _ = f.WriteTo(&builder)
+
+	return builder.String()
+}
+
+// Format returns a copy of the file where all section headers and rules are
+// rendered again with single spaces between their parts, and comments and
+// empty lines are trimmed. Aliases are kept as they are written.
+func (f File) Format() File {
+	formatted := f.clone()
+	formatted.trailing = trimLines(formatted.trailing)
+
+	format := func(src *source) {
+		src.leading = trimLines(src.leading)
+		src.raw = ""
+	}
+
+	for i := range formatted.sections {
+		sec := &formatted.sections[i]
+		format(&sec.src)
+
+		for j := range sec.rules {
+			format(&sec.rules[j].src)
+		}
+	}
+
+	return formatted
+}
+
+func trimLines(lines []string) []string {
+	trimmed := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		trimmed = append(trimmed, strings.TrimSpace(line))
+	}
+
+	return trimmed
+}
+
+// writeLines writes the lines with the line ending, unless a line still has
+// its own line ending, because it differs from the one of the file.
+func writeLines(builder *strings.Builder, lines []string, lineEnding string) {
+	for _, line := range lines {
+		builder.WriteString(line)
+
+		if !strings.HasSuffix(line, "\n") {
+			builder.WriteString(lineEnding)
+		}
+	}
+}
+
+func sectionHeaderLine(sec section) string {
+	if sec.src.raw != "" {
+		return sec.src.raw
+	}
+
+	return renderSectionHeader(sec)
+}
+
+func ruleLine(r rule) string {
+	if r.src.raw != "" {
+		return r.src.raw
+	}
+
+	return renderRule(r)
+}
+
+func renderSectionHeader(sec section) string {
+	header := fmt.Sprintf("[%s]", sec.name)
+
+	switch {
+	case sec.optional:
+		header = "^" + header
+	case sec.approvals > 1:
+		header = fmt.Sprintf("%s[%d]", header, sec.approvals)
+	}
+
+	owners := sec.owners
+	if sec.aliasedOwners != nil {
+		owners = sec.aliasedOwners
+	}
+
+	return withComment(strings.Join(append([]string{header}, owners...), " "), sec.comment)
+}
+
+func renderRule(r rule) string {
+	parts := []string{r.pattern.value}
+	selected := map[string]bool{}
+
+	for _, selection := range r.selections {
+		for _, owner := range selection.Owners {
+			selected[owner] = true
+		}
+	}
+
+	owners := r.owners
+	if r.aliasedOwners != nil {
+		owners = r.aliasedOwners
+	}
+
+	for _, owner := range owners {
+		if !selected[owner] {
+			parts = append(parts, owner)
+		}
+	}
+
+	for _, selection := range r.selections {
+		args := append(append([]string{}, selection.Owners...), fmt.Sprint(selection.Count))
+		parts = append(parts, fmt.Sprintf("%s(%s)", selection.Strategy, strings.Join(args, ", ")))
+	}
+
+	return withComment(strings.Join(parts, " "), r.comment)
+}
+
+func withComment(line, comment string) string {
+	if comment == "" {
+		return line
+	}
+
+	return line + " # " + comment
+}
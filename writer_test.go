@@ -0,0 +1,210 @@
+package gitlabcodeowners
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriter_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "empty file",
+			input: "",
+		},
+		{
+			name:  "comments and empty lines",
+			input: "# Required for all files\n*   @general-approvers\n\n\n# trailing comment\n",
+		},
+		{
+			name:  "sections with odd formatting",
+			input: "[Documentation][2]   @docs-team\n  docs/\nREADME.md\t@docs\n\n^[Database] @database-team\nmodel/db/\n",
+		},
+		{
+			name:  "duplicate and empty sections",
+			input: "[Documentation]\ndocs/ @docs\n\n[Empty]\n\n[Database]\nREADME.md @database\n\n[DOCUMENTATION]\nREADME.md  @docs\n",
+		},
+		{
+			name:  "unparsable section",
+			input: "* @group\n\n[Section name\ndocs/ @docs_group\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			builder := strings.Builder{}
+
+			written, err := file.WriteTo(&builder)
+			if err != nil {
+				t.Errorf("Failed to write code owners file: %v", err)
+			}
+
+			if got := builder.String(); got != tt.input {
+				t.Errorf("got %q, wanted %q", got, tt.input)
+			}
+
+			if written != int64(len(tt.input)) {
+				t.Errorf("got %d written bytes, wanted %d", written, len(tt.input))
+			}
+		})
+	}
+}
+
+func TestWriter_Format(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "comments and empty lines",
+			input: "  # Required for all files  \n*   @general-approvers\t# all\n\n\t\n",
+			want:  "# Required for all files\n* @general-approvers # all\n\n\n",
+		},
+		{
+			name:  "sections with odd formatting",
+			input: "[Documentation][2]   @docs-team\n  docs/\nREADME.md\t@docs\n\n^[Database] @database-team\nmodel/db/\n",
+			want:  "[Documentation][2] @docs-team\ndocs/\nREADME.md @docs\n\n^[Database] @database-team\nmodel/db/\n",
+		},
+		{
+			name:  "duplicate sections",
+			input: "[Documentation]\ndocs/ @docs\n\n  [DOCUMENTATION]  \nREADME.md  @docs\n",
+			want:  "[Documentation]\ndocs/ @docs\n\n[DOCUMENTATION]\nREADME.md @docs\n",
+		},
+		{
+			name:  "aliases",
+			input: "# alias @docs = @alice @bob\n  docs/   @docs  \n*.md   @carol\n",
+			want:  "# alias @docs = @alice @bob\ndocs/ @docs\n*.md @carol\n",
+		},
+		{
+			name:  "line endings",
+			input: "*   @a\r\n\r\n",
+			want:  "* @a\r\n\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := NewCodeOwnersFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Failed to create code owners file: %v", err)
+			}
+
+			if got := file.Format().String(); got != tt.want {
+				t.Errorf("got %q, wanted %q", got, tt.want)
+			}
+
+			if got := file.String(); got != tt.input {
+				t.Errorf("formatting changed the original file to %q", got)
+			}
+		})
+	}
+}
+
+func TestWriter_renderSectionHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		section section
+		want    string
+	}{
+		{
+			name:    "required section",
+			section: newSection("Docs", 1, false, []string{}),
+			want:    "[Docs]",
+		},
+		{
+			name:    "required section with approval count and owners",
+			section: newSection("Docs", 2, false, []string{"@a", "@b"}),
+			want:    "[Docs][2] @a @b",
+		},
+		{
+			name:    "optional section",
+			section: newSection("Docs", 0, true, []string{"@a"}),
+			want:    "^[Docs] @a",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := renderSectionHeader(tt.section); got != tt.want {
+				t.Errorf("got %s, wanted %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter_renderRule(t *testing.T) {
+	t.Parallel()
+
+	selection := newRule(newPattern("docs/"), []string{"@alice", "@@Writers"})
+	selection.selections = []ReviewerSelection{
+		{Strategy: SelectionRandom, Count: 2, Owners: []string{"@@Writers"}},
+	}
+
+	commented := newRule(newPattern("/api/"), []string{"@backend"})
+	commented.comment = "owned since Q3"
+
+	tests := []struct {
+		name string
+		rule rule
+		want string
+	}{
+		{
+			name: "no owners",
+			rule: newRule(newPattern("*.md"), []string{}),
+			want: "*.md",
+		},
+		{
+			name: "multiple owners",
+			rule: newRule(newPattern("*.md"), []string{"@a", "b@example.com"}),
+			want: "*.md @a b@example.com",
+		},
+		{
+			name: "reviewer selection",
+			rule: selection,
+			want: "docs/ @alice Random(@@Writers, 2)",
+		},
+		{
+			name: "inline comment",
+			rule: commented,
+			want: "/api/ @backend # owned since Q3",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := renderRule(tt.rule); got != tt.want {
+				t.Errorf("got %s, wanted %s", got, tt.want)
+			}
+		})
+	}
+}